@@ -28,6 +28,7 @@ import (
 	"runtime"
 	"sort"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -44,6 +45,7 @@ func parseFlags() {
 	pflag.Bool("sector-list", false, "Print a list of available satellite sectors")
 	pflag.Bool("product-list", false, "Print a list of available satellite products")
 	pflag.Bool("zoom-list", false, "Print a list of available zoom levels for satellite sectors")
+	pflag.Bool("neighbor-list", false, "Print the neighboring sectors that --navigate can move to")
 
 	pflag.StringP("satellite", "s", "", "Satellite to request imagery for. "+
 		"See --satellite-list for the full list. (Example: goes-17)")
@@ -51,6 +53,9 @@ func parseFlags() {
 		"See --sector-list for the full list. (Example: conus)")
 	pflag.StringP("product", "p", "", "Satellite product to request imagery for. "+
 		"See --product-list for the full list. (Example: geocolor)")
+	pflag.StringSlice("navigate", []string{}, "Move from --satellite and --sector to the neighboring sector in "+
+		"each direction in turn like the arrows in SLIDER. Directions are 'up', 'right', 'down', and 'left'. "+
+		"The same --product is used. See --neighbor-list for the neighbors. (Example: left,left)")
 	pflag.IntP("zoom", "z", 1, "Zoom level (changes resolution). "+
 		"See --zoom-list for the full list of allowed zoom levels.")
 	pflag.IntP("image-count", "i", 6, "Number of images in the loop.")
@@ -63,12 +68,139 @@ func parseFlags() {
 	pflag.Int("angle", 0, "Degrees to rotate the animation.")
 	pflag.IntSlice("crop", []int{}, "List of points in the final image (before rotation) to crop to. "+
 		"Use the format X1,Y1,X2,Y2 for the rectangle you want to crop to.")
+	pflag.StringSlice("bbox", []string{}, "Geographic bounding box to crop to. Use the format "+
+		"minLon,minLat,maxLon,maxLat in degrees. Only available for sectors that support latitude/longitude "+
+		"lookups. This flag cannot be used with --crop or --center.")
+	pflag.StringSlice("center", []string{}, "Geographic point to center the crop area on. Use the format "+
+		"lat,lon in degrees. Requires --size. This flag cannot be used with --crop or --bbox.")
+	pflag.String("track", "", "Track file of a moving feature, such as a storm, to center the crop area on "+
+		"in each frame. Use a CSV file with time,lat,lon columns or a .json file with a list of objects with "+
+		"time, lat, and lon keys. Requires --size. This flag cannot be used with --crop, --bbox, or --center.")
+	pflag.String("size", "", "Size of the crop area centered on --center or --track in pixels. Use the "+
+		"format WxH.")
 	pflag.StringP("loop", "l", "forward", "Loop style. Options are 'forward', 'reverse', "+
 		"or 'rock'. Note that using 'rock' will nearly double the output animation file size.")
+	pflag.Int("loop-count", 0, "Number of times GIF, PNG, and WebP animations play. Use 0 to play forever.")
+	pflag.Int("first-delay", 0, "Time to display the first frame in 100ths of a second. (default --speed)")
+	pflag.Int("last-delay", 0, "Time to display the last frame in 100ths of a second so the animation pauses "+
+		"before starting over. (default --speed)")
+	pflag.Bool("proportional-delay", false, "Display each frame for a time proportional to the time until the "+
+		"next image was captured so that gaps in the data don't look like sudden jumps.")
+	pflag.Int("interpolate", 0, "Number of frames to synthesize between each pair of images to make the loop "+
+		"smoother. Each synthesized frame is displayed for --speed so consider lowering --speed.")
+	pflag.String("interpolation-mode", "crossfade", "How frames are synthesized by --interpolate. Options are "+
+		"'crossfade' and 'motion'. The 'motion' mode estimates the motion between images with optical flow.")
+	pflag.StringSlice("compare", []string{}, "Other loops to tile next to this loop in a comparison animation "+
+		"with the same capture times. Use a product to compare with the same satellite and sector or "+
+		"satellite:sector:product. (Example: band-13 or goes-17:conus:geocolor)")
+	pflag.String("compare-layout", "", "Rows and columns of the comparison panels set by --compare. Options are "+
+		"'1x2', '2x1', and '2x2'. (default '1x2' for 2 panels and '2x2' for 4 panels)")
+	pflag.String("compare-mode", "tile", "How the panels set by --compare are shown. Options are 'tile', "+
+		"'swipe', and 'flicker'. The 'swipe' mode sweeps a divider across the frame to reveal the --compare "+
+		"product under this loop and 'flicker' alternates between them at each capture time. Both require one "+
+		"--compare product of the same satellite and sector.")
+	pflag.Int("swipe-steps", slider.DefaultSwipeSteps, "Number of frames showing each capture time in the "+
+		"'swipe' --compare-mode.")
+	pflag.StringSlice("compare-titles", []string{}, "Title of each comparison panel starting with this loop. "+
+		"(default satellite and product titles)")
+	pflag.StringSlice("mosaic", []string{}, "Other satellites to combine with this satellite into a mosaic "+
+		"reprojected onto a latitude/longitude grid covering --bbox, or the whole world if --bbox isn't set. "+
+		"The same sector and product are used for each satellite. (Example: goes-17,himawari)")
+	pflag.Float64("mosaic-blend", slider.DefaultMosaicBlendWidth, "Width in degrees of the seams between "+
+		"satellites that are blended together in a --mosaic.")
+	pflag.Int("mosaic-width", 0, "Width in pixels of a --mosaic. (default the resolution of --zoom)")
+	pflag.String("difference", "", "Replace the imagery with the change in brightness of each pixel drawn in red "+
+		"for increases and blue for decreases. Options are 'consecutive' to compare each frame with the previous "+
+		"frame and 'reference' to compare each frame with --difference-reference.")
+	pflag.Int("difference-reference", 0, "Index of the frame compared with in the 'reference' --difference mode "+
+		"starting from 0 for the earliest frame.")
+	pflag.Float64("difference-threshold", 0, "Smallest change in brightness from 0 to 1 highlighted by "+
+		"--difference. Smaller changes are drawn in the neutral color.")
+	pflag.Float64("difference-range", slider.DefaultDifferenceRange, "Change in brightness from 0 to 1 drawn "+
+		"with the strongest colors by --difference.")
+	pflag.String("playlist", "", "YAML playlist of loops to play one after another in a single animation. The "+
+		"playlist sets crossfade, title_frames, width, height, a title for the HTML viewer, and a list of segments. "+
+		"Each segment can set satellite, sector, product, zoom, images, time_step, begin, end, loop, bbox, and a "+
+		"title shown on a title card. Segments use the loop flags for any options they don't set.")
 	pflag.String("decode", "", "Decode a SLIDER URL into a loop config and create an animation. "+
 		"You must supply --time-step as well as that can't be decoded from the URL.")
-	pflag.StringP("format", "f", "gif", "Output animation file format. Options are \"gif\" or"+
-		" \"png\".")
+	pflag.StringP("format", "f", "gif", "Output animation file format. Options are \"gif\", \"png\", "+
+		"\"webp\", \"mp4\", \"webm\", \"frames\", \"contact-sheet\", \"html\", or \"geopng\". The \"frames\" format "+
+		"saves each frame as a separate image in a directory with a manifest.json file. The \"contact-sheet\" "+
+		"format saves the frames as thumbnails in a grid in a single image. The \"html\" format saves a web page "+
+		"that plays the loop offline with controls to pause, step, and change the speed. The \"geopng\" format "+
+		"saves each frame as a separate PNG with a world file and projection file for use in GIS software. Videos "+
+		"are encoded with ffmpeg, or saved as a Motion-JPEG AVI if ffmpeg can't be found.")
+
+	pflag.Int("width", 0, "Width in pixels to resize the animation to. The aspect ratio is preserved.")
+	pflag.Int("height", 0, "Height in pixels to resize the animation to. The aspect ratio is preserved. If "+
+		"--width is also set the animation is resized to fit within both.")
+	pflag.Int("max-dim", 0, "Maximum width and height in pixels of the animation. Smaller animations are "+
+		"not enlarged.")
+	pflag.String("resample", "lanczos", "Resampling filter used to resize the animation. Options are "+
+		"'lanczos', 'catmullrom', and 'nearest'.")
+	pflag.Bool("auto-zoom", false, "Automatically use the smallest zoom level that meets the size set by "+
+		"--width, --height, or --max-dim. Pixel values for --crop and --size are given at --zoom.")
+
+	pflag.Int("gif-colors", 256, "Number of colors in the GIF palette from 2 to 256. Fewer colors create "+
+		"smaller files.")
+	pflag.Bool("gif-global-palette", false, "Use one palette for every GIF frame to prevent colors from "+
+		"flickering between frames.")
+	pflag.Bool("gif-dither", false, "Use Floyd-Steinberg dithering to smooth color gradients in GIF frames.")
+	pflag.Bool("no-gif-optimize", false, "Write every GIF frame as a full-size image instead of only the "+
+		"pixels that changed from the previous frame.")
+	pflag.Bool("webp-lossless", false, "Use lossless compression for WebP animations.")
+	pflag.Int("webp-quality", slider.DefaultWebPQuality, "Quality of lossy WebP compression from 1 to 100. "+
+		"Higher qualities create larger files.")
+	pflag.String("frame-format", "png", "Image format of frames saved by --format=frames and --format=html and "+
+		"of sheets saved by --format=contact-sheet. Options are 'png' and 'jpeg'.")
+	pflag.Int("jpeg-quality", slider.DefaultJPEGQuality, "Quality of JPEG frames from 1 to 100.")
+	pflag.Bool("html-frames-dir", false, "Save the frames of --format=html in a directory next to the HTML file "+
+		"instead of embedding them in the file.")
+	pflag.Int("sheet-columns", slider.DefaultContactSheetColumns, "Number of thumbnails in each row of a "+
+		"contact sheet.")
+	pflag.Int("sheet-every", 1, "Include every Nth frame in a contact sheet.")
+	pflag.Int("sheet-thumb-width", 320, "Width in pixels of each contact sheet thumbnail. Use 0 to keep the "+
+		"frame width.")
+	pflag.Int("sheet-spacing", 8, "Space in pixels between contact sheet thumbnails.")
+	pflag.String("sheet-background", "#ffffff", "Color of the contact sheet background as #RRGGBB or "+
+		"#RRGGBBAA.")
+	pflag.String("ffmpeg", slider.DefaultVideoEncoder, "Path to the ffmpeg executable used to encode videos.")
+	pflag.String("video-codec", "", "ffmpeg video codec used to encode videos. (default \"libx264\" for mp4 "+
+		"and \"libvpx-vp9\" for webm)")
+	pflag.Int("video-crf", 0, "Constant rate factor used to encode videos. Lower values create higher quality "+
+		"and larger files. (default 23 for mp4 and 31 for webm)")
+	pflag.String("max-bytes", "", "Maximum file size of the animation. The animation is reduced until it fits. "+
+		"Use a number of bytes or a size such as '500K' or '8M'.")
+	pflag.StringSlice("max-bytes-priority", []string{"colors", "dimensions", "frames"}, "Order of the "+
+		"reductions used to fit --max-bytes. Reductions that are not listed are not used. Color reduction only "+
+		"applies to GIF animations.")
+
+	pflag.Bool("label-time", false, "Draw the image capture time onto each frame.")
+	pflag.String("label-time-format", slider.DefaultTimeFormat, "Format of the capture time drawn by "+
+		"--label-time using the Go time layout.")
+	pflag.String("label-time-zone", "UTC", "Time zone of the capture time drawn by --label-time. "+
+		"(Example: America/New_York)")
+	pflag.Bool("label-title", false, "Draw the satellite and product title onto each frame.")
+	pflag.String("caption", "", "Custom caption to draw onto each frame.")
+	pflag.String("label-position", "top-left", "Corner to draw labels in. Options are 'top-left', "+
+		"'top-right', 'bottom-left', and 'bottom-right'.")
+	pflag.Int("label-size", 0, "Font size of labels in pixels. (default relative to image size)")
+	pflag.String("label-color", "#ffffff", "Color of label text as #RRGGBB or #RRGGBBAA.")
+	pflag.String("label-background", "#00000099", "Color of the box drawn behind labels as #RRGGBB "+
+		"or #RRGGBBAA. Use 'none' to disable the box.")
+	pflag.String("legend", "none", "Attach the product's color table legend to the animation. Options are "+
+		"'none' and 'below'.")
+	pflag.Bool("legend-offline", false, "Use the color tables stored in the --cache directory or bundled with "+
+		"slider-cli for --legend instead of downloading them from SLIDER.")
+	pflag.String("watermark", "", "Path to an image to draw onto each frame as a watermark.")
+	pflag.String("watermark-position", "bottom-right", "Corner to draw the watermark in. Options are "+
+		"'top-left', 'top-right', 'bottom-left', and 'bottom-right'.")
+	pflag.Float64("watermark-opacity", 0.8, "Opacity of the watermark from 0 to 1.")
+	pflag.Float64("watermark-scale", slider.DefaultWatermarkScale, "Width of the watermark as a fraction of "+
+		"the image width.")
+	pflag.Bool("footer", false, "Attach an attribution footer below each frame.")
+	pflag.String("footer-text", slider.DefaultAttribution, "Text drawn in the attribution footer.")
 
 	pflag.Bool("help", false, "Print help dialog.")
 	pflag.Bool("help-wrapped", false, "Print help dialog with text wrapped.")
@@ -182,6 +314,10 @@ func handleFlags(config *viper.Viper) {
 		os.Exit(0)
 	}
 
+	if _, err := compareLayout(config); err != nil {
+		log.Fatal().Msgf("Invalid comparison options: %v", err)
+	}
+
 	inventory, err := slider.GetProductInventory()
 	if err != nil {
 		log.Fatal().Msgf("unable to load product inventory: %v", err)
@@ -294,6 +430,48 @@ func handleFlags(config *viper.Viper) {
 		}
 	}
 
+	if directions := config.GetStringSlice("navigate"); len(directions) > 0 {
+		if satellite == nil || sector == nil {
+			log.Fatal().Msg("You must set --satellite and --sector first to navigate.")
+		}
+		var err error
+		satellite, sector, product, err = inventory.Navigate(satellite, sector, product, directions...)
+		if err != nil {
+			log.Fatal().Msgf("Unable to navigate: %v", err)
+		}
+		log.Debug().Msgf("Navigated to sector %s on satellite %s", sector.ID(), satellite.ID())
+	}
+
+	if config.GetBool("neighbor-list") {
+		if satellite == nil || sector == nil {
+			log.Fatal().Msg("You must set --satellite and --sector first to list neighboring sectors.")
+		}
+		fmt.Printf("Neighbors of Sector %s on Satellite %s\n", sector.SectorTitle, satellite.SatelliteTitle)
+		for _, name := range slider.NavigationDirections {
+			direction, err := sector.Navigation.Direction(name)
+			if err != nil {
+				log.Fatal().Msgf("%v", err)
+			}
+			if direction == nil {
+				continue
+			}
+			neighborSatellite, neighborSector, err := direction.Resolve(inventory)
+			if err != nil {
+				fmt.Printf("%10s = %v\n", name, err)
+				continue
+			}
+			line := fmt.Sprintf("%10s = %s %s (%s - %s)", name, neighborSatellite.ID(), neighborSector.ID(),
+				neighborSatellite.SatelliteTitle, neighborSector.SectorTitle)
+			if product != nil {
+				if _, _, _, err := direction.ResolveProduct(inventory, product); err != nil {
+					line += " -- " + product.ID() + " not available"
+				}
+			}
+			fmt.Println(line)
+		}
+		os.Exit(0)
+	}
+
 	if config.GetBool("date-list") {
 		if satellite == nil || sector == nil || product == nil {
 			log.Fatal().Msg("You must set --satellite, --sector, and --product first to see available dates.")
@@ -309,21 +487,14 @@ func handleFlags(config *viper.Viper) {
 		os.Exit(0)
 	}
 
-	if satellite == nil || sector == nil || product == nil {
+	playlistFile := config.GetString("playlist")
+	if playlistFile == "" && (satellite == nil || sector == nil || product == nil) {
 		log.Fatal().Msg("You must set --satellite, --sector, and --product to create a new loop.")
 	}
 
-	var loop slider.LoopStyle
-	switch config.GetString("loop") {
-	case "forward":
-		loop = slider.ForwardLoop
-	case "reverse":
-		loop = slider.ReverseLoop
-	case "rock":
-		loop = slider.RockLoop
-	default:
-		log.Fatal().Msgf("Loop style '%s' is not valid. Options are 'forward', 'reverse', and 'rock'.",
-			config.GetString("loop"))
+	loop, err := slider.ParseLoopStyle(config.GetString("loop"))
+	if err != nil {
+		log.Fatal().Msgf("Invalid --loop: %v", err)
 	}
 
 	var beginTime time.Time
@@ -356,36 +527,641 @@ func handleFlags(config *viper.Viper) {
 		}
 	}
 
+	var boundingBox *slider.BoundingBox
+	if values := config.GetStringSlice("bbox"); len(values) > 0 {
+		if cropArea != nil {
+			log.Fatal().Msg("--bbox cannot be used with --crop.")
+		}
+		points, err := parseFloats(values, 4)
+		if err != nil {
+			log.Fatal().Msgf("Unable to parse --bbox: %v", err)
+		}
+		boundingBox = &slider.BoundingBox{MinLon: points[0], MinLat: points[1], MaxLon: points[2], MaxLat: points[3]}
+	}
+
+	var center *slider.LatLon
+	var centerSize image.Point
+	if values := config.GetStringSlice("center"); len(values) > 0 {
+		if cropArea != nil || boundingBox != nil {
+			log.Fatal().Msg("--center cannot be used with --crop or --bbox.")
+		}
+		points, err := parseFloats(values, 2)
+		if err != nil {
+			log.Fatal().Msgf("Unable to parse --center: %v", err)
+		}
+		center = &slider.LatLon{Lat: points[0], Lon: points[1]}
+	}
+
+	var track *slider.Track
+	if trackFile := config.GetString("track"); trackFile != "" {
+		if cropArea != nil || boundingBox != nil || center != nil {
+			log.Fatal().Msg("--track cannot be used with --crop, --bbox, or --center.")
+		}
+		var err error
+		track, err = slider.LoadTrack(trackFile)
+		if err != nil {
+			log.Fatal().Msgf("Unable to load track: %v", err)
+		}
+	}
+
+	if config.GetString("size") != "" && center == nil && track == nil {
+		log.Fatal().Msg("--size requires --center or --track.")
+	}
+	if center != nil || track != nil {
+		var err error
+		centerSize, err = parseSize(config.GetString("size"))
+		if err != nil {
+			log.Fatal().Msgf("Unable to parse --size: %v", err)
+		}
+	}
+
 	var fileFormat slider.FileFormat
+	var videoContainer string
 	switch config.GetString("format") {
 	case "gif", "GIF":
 		fileFormat = slider.GIF
 	case "png", "PNG":
 		fileFormat = slider.PNG
+	case "webp", "WEBP":
+		fileFormat = slider.WebP
+	case "mp4", "MP4", "webm", "WEBM":
+		fileFormat = slider.Video
+		videoContainer = strings.ToLower(config.GetString("format"))
+	case "frames", "FRAMES":
+		fileFormat = slider.Frames
+	case "contact-sheet", "CONTACT-SHEET":
+		fileFormat = slider.ContactSheet
+	case "html", "HTML":
+		fileFormat = slider.HTML
+	case "geopng", "GEOPNG":
+		fileFormat = slider.GeoPNG
 	default:
-		log.Fatal().Msgf("File format '%s' is not valid. Options are 'gif' and 'png'.",
-			config.GetString("format"))
+		log.Fatal().Msgf("File format '%s' is not valid. Options are 'gif', 'png', 'webp', 'mp4', 'webm', "+
+			"'frames', 'contact-sheet', 'html', and 'geopng'.", config.GetString("format"))
+	}
+
+	annotation, err := annotationOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid label options: %v", err)
+	}
+
+	if colors := config.GetInt("gif-colors"); colors < 2 || colors > 256 {
+		log.Fatal().Msgf("Number of GIF colors must be between 2 and 256: %d", colors)
+	}
+	gifOptions := &slider.GIFOptions{
+		Dither:        config.GetBool("gif-dither"),
+		GlobalPalette: config.GetBool("gif-global-palette"),
+		NumColors:     config.GetInt("gif-colors"),
+		Optimize:      !config.GetBool("no-gif-optimize"),
 	}
 
-	err = slider.CreateLoop(&slider.LoopOptions{
+	if quality := config.GetInt("webp-quality"); quality < 1 || quality > 100 {
+		log.Fatal().Msgf("WebP quality must be between 1 and 100: %d", quality)
+	}
+	webpOptions := &slider.WebPOptions{
+		Lossless: config.GetBool("webp-lossless"),
+		Quality:  config.GetInt("webp-quality"),
+	}
+
+	if crf := config.GetInt("video-crf"); crf < 0 || crf > 63 {
+		log.Fatal().Msgf("Video CRF must be between 0 and 63: %d", crf)
+	}
+	videoOptions := &slider.VideoOptions{
+		Codec:     config.GetString("video-codec"),
+		Container: videoContainer,
+		CRF:       config.GetInt("video-crf"),
+		Encoder:   config.GetString("ffmpeg"),
+	}
+
+	if quality := config.GetInt("jpeg-quality"); quality < 1 || quality > 100 {
+		log.Fatal().Msgf("JPEG quality must be between 1 and 100: %d", quality)
+	}
+	framesOptions := &slider.FramesOptions{Quality: config.GetInt("jpeg-quality")}
+	switch config.GetString("frame-format") {
+	case "png", "PNG":
+	case "jpeg", "JPEG", "jpg", "JPG":
+		framesOptions.JPEG = true
+	default:
+		log.Fatal().Msgf("Frame format '%s' is not valid. Options are 'png' and 'jpeg'.",
+			config.GetString("frame-format"))
+	}
+
+	htmlOptions := &slider.HTMLOptions{
+		ExternalFrames: config.GetBool("html-frames-dir"),
+		JPEG:           framesOptions.JPEG,
+		Quality:        framesOptions.Quality,
+	}
+
+	contactSheet, err := contactSheetOptions(config, framesOptions)
+	if err != nil {
+		log.Fatal().Msgf("Invalid contact sheet options: %v", err)
+	}
+
+	var interpolation *slider.InterpolationOptions
+	if frames := config.GetInt("interpolate"); frames != 0 {
+		if frames < 0 {
+			log.Fatal().Msgf("Number of interpolated frames can't be negative: %d", frames)
+		}
+		mode, err := slider.ParseInterpolationMode(config.GetString("interpolation-mode"))
+		if err != nil {
+			log.Fatal().Msgf("Invalid --interpolation-mode: %v", err)
+		}
+		interpolation = &slider.InterpolationOptions{Frames: frames, Mode: mode}
+	}
+
+	timing, err := timingOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid frame timing options: %v", err)
+	}
+
+	resize, err := resizeOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid resize options: %v", err)
+	}
+
+	budget, err := budgetOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid --max-bytes options: %v", err)
+	}
+
+	watermark, err := watermarkOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid watermark options: %v", err)
+	}
+	var footer *slider.FooterOptions
+	if config.GetBool("footer") {
+		footer = &slider.FooterOptions{Text: config.GetString("footer-text")}
+	}
+
+	var legend *slider.LegendOptions
+	switch config.GetString("legend") {
+	case "none":
+	case "below":
+		if product != nil && product.ColorTableName == "" {
+			log.Warn().Msgf("Product '%s' does not have a color table -- no legend will be attached.", product.ID())
+			break
+		}
+		legend = &slider.LegendOptions{Offline: config.GetBool("legend-offline")}
+	default:
+		log.Fatal().Msgf("Legend position '%s' is not valid. Options are 'none' and 'below'.",
+			config.GetString("legend"))
+	}
+
+	if playlistFile != "" {
+		if cropArea != nil || boundingBox != nil || center != nil || track != nil {
+			log.Fatal().Msg("--crop, --bbox, --center, and --track cannot be used with --playlist. Set a bbox for " +
+				"each playlist segment instead.")
+		}
+		if len(config.GetStringSlice("mosaic")) > 0 || len(config.GetStringSlice("compare")) > 0 {
+			log.Fatal().Msg("--mosaic and --compare cannot be used with --playlist.")
+		}
+	}
+
+	mosaic, err := mosaicOptions(config, inventory)
+	if err != nil {
+		log.Fatal().Msgf("Invalid mosaic options: %v", err)
+	}
+	if mosaic != nil && (cropArea != nil || center != nil || track != nil) {
+		log.Fatal().Msg("--mosaic can only be cropped with --bbox.")
+	}
+
+	difference, err := differenceOptions(config)
+	if err != nil {
+		log.Fatal().Msgf("Invalid difference options: %v", err)
+	}
+
+	compare, err := compareOptions(config, satellite, inventory)
+	if err != nil {
+		log.Fatal().Msgf("Invalid comparison options: %v", err)
+	}
+	if compare != nil && fileFormat == slider.GeoPNG {
+		log.Fatal().Msg("--compare cannot be used with the geopng format.")
+	}
+	if interpolation != nil && fileFormat == slider.GeoPNG {
+		log.Fatal().Msg("--interpolate cannot be used with the geopng format.")
+	}
+	if interpolation != nil && track != nil && fileFormat != slider.ContactSheet {
+		log.Fatal().Msg("--interpolate cannot be used with --track.")
+	}
+
+	opts := &slider.LoopOptions{
 		Satellite:       satellite,
 		Sector:          sector,
 		Product:         product,
 		Loop:            loop,
+		Mosaic:          mosaic,
 		NumberOfImages:  config.GetInt("image-count"),
 		Angle:           float64(config.GetInt("angle")),
+		Annotation:      annotation,
+		BoundingBox:     boundingBox,
+		Center:          center,
+		CenterSize:      centerSize,
+		Compare:         compare,
+		ContactSheet:    contactSheet,
 		Crop:            cropArea,
+		Difference:      difference,
 		Speed:           config.GetInt("speed"),
 		ZoomLevel:       config.GetInt("zoom"),
 		TimeStep:        config.GetInt("time-step"),
+		Timing:          timing,
+		Track:           track,
 		BeginTime:       beginTime,
+		Budget:          budget,
 		EndTime:         endTime,
 		CacheDirectory:  config.GetString("cache"),
 		OutputDirectory: config.GetString("dir"),
 		FileFormat:      fileFormat,
-	})
+		Footer:          footer,
+		Frames:          framesOptions,
+		GIF:             gifOptions,
+		HTML:            htmlOptions,
+		Interpolation:   interpolation,
+		Legend:          legend,
+		Resize:          resize,
+		Video:           videoOptions,
+		Watermark:       watermark,
+		WebP:            webpOptions,
+	}
+	if playlistFile != "" {
+		playlist, err := slider.LoadPlaylist(playlistFile)
+		if err != nil {
+			log.Fatal().Msgf("Unable to load playlist: %v", err)
+		}
+		storyline, err := playlist.Storyline(inventory, opts)
+		if err != nil {
+			log.Fatal().Msgf("Invalid playlist: %v", err)
+		}
+		err = slider.CreateStoryline(storyline)
+		if err != nil {
+			log.Fatal().Msgf("unable to create storyline: %v", err)
+		}
+		os.Exit(0)
+	}
+	err = slider.CreateLoop(opts)
 	if err != nil {
 		log.Fatal().Msgf("unable to create loop: %v", err)
 	}
 	os.Exit(0)
 }
+
+// parseFloats parses exactly count float values.
+func parseFloats(values []string, count int) ([]float64, error) {
+	if len(values) != count {
+		return nil, fmt.Errorf("expected %d values but got %d: %v", count, len(values), values)
+	}
+	floats := make([]float64, count)
+	for i, v := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", v)
+		}
+		floats[i] = f
+	}
+	return floats, nil
+}
+
+// parseSize parses a size in the format WxH.
+func parseSize(value string) (image.Point, error) {
+	parts := strings.Split(strings.ToLower(value), "x")
+	if len(parts) != 2 {
+		return image.Point{}, fmt.Errorf("size must use the format WxH: '%s'", value)
+	}
+	w, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return image.Point{}, fmt.Errorf("invalid width '%s'", parts[0])
+	}
+	h, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return image.Point{}, fmt.Errorf("invalid height '%s'", parts[1])
+	}
+	if w <= 0 || h <= 0 {
+		return image.Point{}, fmt.Errorf("width and height must be greater than zero: '%s'", value)
+	}
+	return image.Point{X: w, Y: h}, nil
+}
+
+// annotationOptions creates the label options from the config. Nil is returned if no labels are enabled.
+func annotationOptions(config *viper.Viper) (*slider.AnnotationOptions, error) {
+	if !config.GetBool("label-time") && !config.GetBool("label-title") && config.GetString("caption") == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(config.GetString("label-time-zone"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone '%s': %w", config.GetString("label-time-zone"), err)
+	}
+	position, err := slider.ParseCorner(config.GetString("label-position"))
+	if err != nil {
+		return nil, err
+	}
+	fg, err := slider.ParseHexColor(config.GetString("label-color"))
+	if err != nil {
+		return nil, err
+	}
+	annotation := &slider.AnnotationOptions{
+		Caption:    config.GetString("caption"),
+		Color:      fg,
+		FontSize:   float64(config.GetInt("label-size")),
+		Location:   location,
+		Position:   position,
+		ShowTime:   config.GetBool("label-time"),
+		ShowTitle:  config.GetBool("label-title"),
+		TimeFormat: config.GetString("label-time-format"),
+	}
+	if bg := config.GetString("label-background"); bg != "none" {
+		annotation.Background, err = slider.ParseHexColor(bg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return annotation, nil
+}
+
+// compareOptions creates the comparison options from the config. Nil is returned if no comparison is set.
+func compareOptions(config *viper.Viper, satellite *slider.Satellite,
+	inventory *slider.ProductInventory) (*slider.CompareOptions, error) {
+	layout, err := compareLayout(config)
+	if err != nil {
+		return nil, err
+	}
+	values := config.GetStringSlice("compare")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	mode, err := slider.ParseCompareMode(config.GetString("compare-mode"))
+	if err != nil {
+		return nil, err
+	}
+	if config.GetInt("swipe-steps") < 1 {
+		return nil, fmt.Errorf("--swipe-steps must be at least 1: %d", config.GetInt("swipe-steps"))
+	}
+	compare := &slider.CompareOptions{
+		Layout:     layout,
+		Mode:       mode,
+		Panels:     []slider.ComparePanel{{}},
+		SwipeSteps: config.GetInt("swipe-steps"),
+	}
+	for _, value := range values {
+		panel := slider.ComparePanel{}
+		parts := strings.Split(strings.TrimSpace(value), ":")
+		switch len(parts) {
+		case 1:
+			panel.Product = satellite.Products[parts[0]]
+		case 3:
+			panel.Satellite = inventory.Satellites[parts[0]]
+			if panel.Satellite == nil {
+				return nil, fmt.Errorf("'%s' is not a valid satellite", parts[0])
+			}
+			panel.Sector = panel.Satellite.Sectors[parts[1]]
+			if panel.Sector == nil {
+				return nil, fmt.Errorf("'%s' is not a valid sector for the '%s' satellite", parts[1], parts[0])
+			}
+			panel.Product = panel.Satellite.Products[parts[2]]
+		default:
+			return nil, fmt.Errorf("panels must use the format product or satellite:sector:product: '%s'", value)
+		}
+		if panel.Product == nil {
+			return nil, fmt.Errorf("'%s' is not a valid product", parts[len(parts)-1])
+		}
+		compare.Panels = append(compare.Panels, panel)
+	}
+	titles := config.GetStringSlice("compare-titles")
+	if len(titles) > len(compare.Panels) {
+		return nil, fmt.Errorf("%d titles were given for %d panels", len(titles), len(compare.Panels))
+	}
+	for i, title := range titles {
+		compare.Panels[i].Title = strings.TrimSpace(title)
+	}
+	return compare, nil
+}
+
+// compareLayout checks the number of --compare panels against the comparison mode and layout and returns the layout
+// of tiled panels. Only the flags are checked so that invalid comparisons fail before the product inventory is
+// downloaded.
+func compareLayout(config *viper.Viper) (slider.CompareLayout, error) {
+	values := config.GetStringSlice("compare")
+	if len(values) == 0 {
+		if config.GetString("compare-layout") != "" || len(config.GetStringSlice("compare-titles")) > 0 {
+			return slider.SideBySide, fmt.Errorf("--compare-layout and --compare-titles require --compare")
+		}
+		return slider.SideBySide, nil
+	}
+	mode, err := slider.ParseCompareMode(config.GetString("compare-mode"))
+	if err != nil {
+		return slider.SideBySide, err
+	}
+	// The first panel is the loop's own product
+	panels := len(values) + 1
+	if mode != slider.TileCompare {
+		if panels != 2 {
+			return slider.SideBySide, fmt.Errorf("the '%s' mode compares with exactly one other product",
+				config.GetString("compare-mode"))
+		}
+		if config.GetString("compare-layout") != "" {
+			return slider.SideBySide, fmt.Errorf("--compare-layout only applies to the 'tile' mode")
+		}
+		return slider.SideBySide, nil
+	}
+	if panels != 2 && panels != 4 {
+		return slider.SideBySide, fmt.Errorf("the 'tile' mode compares 2 or 4 panels but %d were given", panels)
+	}
+	layout := slider.SideBySide
+	if panels == 4 {
+		layout = slider.Grid
+	}
+	if config.GetString("compare-layout") != "" {
+		layout, err = slider.ParseCompareLayout(config.GetString("compare-layout"))
+		if err != nil {
+			return slider.SideBySide, err
+		}
+		if panels != layout.Panels() {
+			return slider.SideBySide, fmt.Errorf("the '%s' layout has room for %d panels but %d were given",
+				config.GetString("compare-layout"), layout.Panels(), panels)
+		}
+	}
+	return layout, nil
+}
+
+// mosaicOptions creates the mosaic options from the config. Nil is returned if no mosaic is set.
+func mosaicOptions(config *viper.Viper, inventory *slider.ProductInventory) (*slider.MosaicOptions, error) {
+	ids := config.GetStringSlice("mosaic")
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	mosaic := &slider.MosaicOptions{
+		BlendWidth: config.GetFloat64("mosaic-blend"),
+		Width:      config.GetInt("mosaic-width"),
+	}
+	for _, id := range ids {
+		satellite := inventory.Satellites[strings.TrimSpace(id)]
+		if satellite == nil {
+			return nil, fmt.Errorf("'%s' is not a valid satellite", id)
+		}
+		mosaic.Satellites = append(mosaic.Satellites, satellite)
+	}
+	if mosaic.BlendWidth <= 0 {
+		return nil, fmt.Errorf("--mosaic-blend must be greater than 0: %v", mosaic.BlendWidth)
+	}
+	if mosaic.Width < 0 {
+		return nil, fmt.Errorf("--mosaic-width can't be negative: %d", mosaic.Width)
+	}
+	return mosaic, nil
+}
+
+// differenceOptions creates the frame difference options from the config. Nil is returned if no difference mode
+// is set.
+func differenceOptions(config *viper.Viper) (*slider.DifferenceOptions, error) {
+	if config.GetString("difference") == "" {
+		return nil, nil
+	}
+	mode, err := slider.ParseDifferenceMode(config.GetString("difference"))
+	if err != nil {
+		return nil, err
+	}
+	difference := &slider.DifferenceOptions{
+		Mode:      mode,
+		Range:     config.GetFloat64("difference-range"),
+		Reference: config.GetInt("difference-reference"),
+		Threshold: config.GetFloat64("difference-threshold"),
+	}
+	if difference.Range <= 0 || difference.Range > 1 {
+		return nil, fmt.Errorf("--difference-range must be greater than 0 and at most 1: %v", difference.Range)
+	}
+	if difference.Threshold < 0 || difference.Threshold >= 1 {
+		return nil, fmt.Errorf("--difference-threshold must be at least 0 and less than 1: %v", difference.Threshold)
+	}
+	if difference.Reference < 0 {
+		return nil, fmt.Errorf("--difference-reference can't be negative: %d", difference.Reference)
+	}
+	return difference, nil
+}
+
+// contactSheetOptions creates the contact sheet options from the config. Sheets are saved in the same image format
+// as frames.
+func contactSheetOptions(config *viper.Viper, frames *slider.FramesOptions) (*slider.ContactSheetOptions, error) {
+	background, err := slider.ParseHexColor(config.GetString("sheet-background"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sheet-background: %w", err)
+	}
+	sheet := &slider.ContactSheetOptions{
+		Background:     background,
+		Columns:        config.GetInt("sheet-columns"),
+		Every:          config.GetInt("sheet-every"),
+		JPEG:           frames.JPEG,
+		Quality:        frames.Quality,
+		Spacing:        config.GetInt("sheet-spacing"),
+		ThumbnailWidth: config.GetInt("sheet-thumb-width"),
+	}
+	if sheet.Columns < 1 {
+		return nil, fmt.Errorf("--sheet-columns must be at least 1: %d", sheet.Columns)
+	}
+	if sheet.Every < 1 {
+		return nil, fmt.Errorf("--sheet-every must be at least 1: %d", sheet.Every)
+	}
+	if sheet.Spacing < 0 {
+		return nil, fmt.Errorf("--sheet-spacing can't be negative: %d", sheet.Spacing)
+	}
+	if sheet.ThumbnailWidth < 0 {
+		return nil, fmt.Errorf("--sheet-thumb-width can't be negative: %d", sheet.ThumbnailWidth)
+	}
+	return sheet, nil
+}
+
+// budgetOptions creates the file size budget options from the config. Nil is returned if no budget is set.
+func budgetOptions(config *viper.Viper) (*slider.BudgetOptions, error) {
+	value := strings.ToUpper(strings.TrimSpace(config.GetString("max-bytes")))
+	if value == "" {
+		return nil, nil
+	}
+	value = strings.TrimSuffix(value, "B")
+	multiplier := int64(1)
+	if strings.HasSuffix(value, "K") {
+		value, multiplier = strings.TrimSuffix(value, "K"), 1000
+	} else if strings.HasSuffix(value, "M") {
+		value, multiplier = strings.TrimSuffix(value, "M"), 1000*1000
+	}
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("invalid size '%s'", config.GetString("max-bytes"))
+	}
+	budget := &slider.BudgetOptions{MaxBytes: int64(size * float64(multiplier))}
+	for _, name := range config.GetStringSlice("max-bytes-priority") {
+		reduction, err := slider.ParseReduction(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		budget.Priority = append(budget.Priority, reduction)
+	}
+	if len(budget.Priority) == 0 {
+		return nil, fmt.Errorf("at least one reduction must be listed in --max-bytes-priority")
+	}
+	return budget, nil
+}
+
+// timingOptions creates the frame timing options from the config. Nil is returned if every frame uses --speed.
+func timingOptions(config *viper.Viper) (*slider.TimingOptions, error) {
+	timing := &slider.TimingOptions{
+		FirstDelay:   config.GetInt("first-delay"),
+		LastDelay:    config.GetInt("last-delay"),
+		LoopCount:    config.GetInt("loop-count"),
+		Proportional: config.GetBool("proportional-delay"),
+	}
+	if timing.FirstDelay < 0 || timing.LastDelay < 0 {
+		return nil, fmt.Errorf("--first-delay and --last-delay can't be negative")
+	}
+	if timing.LoopCount < 0 || timing.LoopCount > 65535 {
+		return nil, fmt.Errorf("--loop-count must be between 0 and 65535: %d", timing.LoopCount)
+	}
+	if *timing == (slider.TimingOptions{}) {
+		return nil, nil
+	}
+	return timing, nil
+}
+
+// resizeOptions creates the resize options from the config. Nil is returned if no size is set.
+func resizeOptions(config *viper.Viper) (*slider.ResizeOptions, error) {
+	width, height, maxDim := config.GetInt("width"), config.GetInt("height"), config.GetInt("max-dim")
+	if width < 0 || height < 0 || maxDim < 0 {
+		return nil, fmt.Errorf("sizes can't be negative")
+	}
+	if width == 0 && height == 0 && maxDim == 0 {
+		if config.GetBool("auto-zoom") {
+			return nil, fmt.Errorf("--auto-zoom requires --width, --height, or --max-dim")
+		}
+		return nil, nil
+	}
+	filter, err := slider.ParseResampleFilter(config.GetString("resample"))
+	if err != nil {
+		return nil, err
+	}
+	return &slider.ResizeOptions{
+		AutoZoom:     config.GetBool("auto-zoom"),
+		Filter:       filter,
+		Height:       height,
+		MaxDimension: maxDim,
+		Width:        width,
+	}, nil
+}
+
+// watermarkOptions creates the watermark options from the config. Nil is returned if no watermark is set.
+func watermarkOptions(config *viper.Viper) (*slider.WatermarkOptions, error) {
+	if config.GetString("watermark") == "" {
+		return nil, nil
+	}
+	opacity := config.GetFloat64("watermark-opacity")
+	if opacity < 0 || opacity > 1 {
+		return nil, fmt.Errorf("opacity must be between 0 and 1: %v", opacity)
+	}
+	scale := config.GetFloat64("watermark-scale")
+	if scale <= 0 || scale > 1 {
+		return nil, fmt.Errorf("scale must be greater than 0 and at most 1: %v", scale)
+	}
+	position, err := slider.ParseCorner(config.GetString("watermark-position"))
+	if err != nil {
+		return nil, err
+	}
+	img, err := slider.LoadWatermark(config.GetString("watermark"))
+	if err != nil {
+		return nil, err
+	}
+	return &slider.WatermarkOptions{Image: img, Opacity: opacity, Position: position, Scale: scale}, nil
+}
@@ -17,12 +17,14 @@ package slider
 
 import (
 	"fmt"
-	"github.com/andybons/gogif"
 	"github.com/kettek/apng"
 	"github.com/rs/zerolog/log"
 	"image"
+	"image/color"
 	"image/gif"
+	"io/ioutil"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -41,12 +43,79 @@ const (
 	RockLoop
 )
 
+// ParseLoopStyle parses a loop style given as "forward", "reverse", or "rock".
+func ParseLoopStyle(s string) (LoopStyle, error) {
+	switch strings.ToLower(s) {
+	case "forward":
+		return ForwardLoop, nil
+	case "reverse":
+		return ReverseLoop, nil
+	case "rock":
+		return RockLoop, nil
+	default:
+		return ForwardLoop, fmt.Errorf("unknown loop style '%s': options are 'forward', 'reverse', and 'rock'", s)
+	}
+}
+
+// GIFOptions are the options used to encode GIF animations.
+type GIFOptions struct {
+	// Optimize writes only the area of each frame that changed from the previous frame and makes the unchanged
+	// pixels transparent. By default every frame is written as a full-size image.
+	Optimize bool
+	// Dither uses Floyd-Steinberg dithering when reducing frames to the palette colors. This smooths gradients at
+	// the cost of larger files.
+	Dither bool
+	// GlobalPalette uses a single palette computed from a sample of every frame instead of a palette for each frame.
+	// This prevents colors from flickering between frames.
+	GlobalPalette bool
+	// NumColors is the number of colors in the palette of each frame from 2 to 256. 256 colors are used if NumColors
+	// is zero. When optimizing, one of the colors is reserved for transparency.
+	NumColors int
+
+	// delays is the delay of each frame in display order, or nil if every frame uses the same delay.
+	delays []int
+}
+
 // AnimateGIF animates the supplied images into a GIF image. This will convert RGB images to a 256-color palette
 // due to the GIF color limit of 256. This will likely result in some down-sampling of your image colors.
 func AnimateGIF(images []image.Image, delay int, style LoopStyle) (*gif.GIF, error) {
+	return AnimateGIFWithOptions(images, delay, style, &GIFOptions{})
+}
+
+// AnimateGIFWithOptions animates the supplied images into a GIF image using the GIF options.
+func AnimateGIFWithOptions(images []image.Image, delay int, style LoopStyle, opts *GIFOptions) (*gif.GIF, error) {
+	numColors := opts.NumColors
+	if numColors == 0 {
+		numColors = 256
+	}
+	if numColors < 2 || numColors > 256 {
+		return nil, fmt.Errorf("number of colors must be between 2 and 256: %d", numColors)
+	}
+	quantizeColors := numColors
+	if opts.Optimize {
+		quantizeColors--
+	}
 	newGIF := new(gif.GIF)
 	log.Debug().Msgf("Animating %d images", len(images))
 	timeIn := time.Now()
+	var palette color.Palette
+	if opts.GlobalPalette && len(images) > 0 {
+		palette = samplePalette(images, quantizeColors)
+		if opts.Optimize {
+			// The transparent color used by optimization is included so that every frame can share the palette
+			palette = append(palette, color.RGBA{})
+		}
+		var size image.Point
+		for _, img := range images {
+			if img.Bounds().Max.X > size.X {
+				size.X = img.Bounds().Max.X
+			}
+			if img.Bounds().Max.Y > size.Y {
+				size.Y = img.Bounds().Max.Y
+			}
+		}
+		newGIF.Config = image.Config{ColorModel: palette, Width: size.X, Height: size.Y}
+	}
 	wg := sync.WaitGroup{}
 	lock := sync.Mutex{}
 	switch style {
@@ -62,9 +131,7 @@ func AnimateGIF(images []image.Image, delay int, style LoopStyle) (*gif.GIF, err
 	for i, img := range images {
 		wg.Add(1)
 		go func(i int, img image.Image) {
-			palettedImage := image.NewPaletted(img.Bounds(), nil)
-			quantizer := gogif.MedianCutQuantizer{NumColor: 256}
-			quantizer.Quantize(palettedImage, img.Bounds(), img, image.Point{})
+			palettedImage := quantizeFrame(img, palette, quantizeColors, opts.Dither)
 			lock.Lock()
 			switch style {
 			case ForwardLoop:
@@ -84,6 +151,17 @@ func AnimateGIF(images []image.Image, delay int, style LoopStyle) (*gif.GIF, err
 		}(i, img)
 	}
 	wg.Wait()
+	if opts.delays != nil {
+		if len(opts.delays) != len(newGIF.Delay) {
+			return nil, fmt.Errorf("animation has %d frames but there are %d delays", len(newGIF.Delay),
+				len(opts.delays))
+		}
+		// Delays are set before optimizing since unchanged frames are merged into the previous frame
+		copy(newGIF.Delay, opts.delays)
+	}
+	if opts.Optimize {
+		optimizeGIF(newGIF)
+	}
 	timeOut := time.Now()
 	log.Debug().Msgf("Animation took %.3fs", timeOut.Sub(timeIn).Seconds())
 	return newGIF, nil
@@ -167,6 +245,21 @@ func SavePNG(output string, img *apng.APNG) (string, error) {
 	return output + ".png", nil
 }
 
+// saveEncoded saves an animation that has already been encoded into a file with the suffix. If a file with the same
+// name exists an incrementing number will be appended to the end of the file name.
+func saveEncoded(output, suffix string, data []byte) (string, error) {
+	output, err := checkFileDuplicate(output, suffix)
+	if err != nil {
+		return "", err
+	}
+	err = ioutil.WriteFile(output+suffix, data, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to write animation: %w", err)
+	}
+	log.Debug().Msgf("Saved animation to '%s'", output+suffix)
+	return output + suffix, nil
+}
+
 func checkFileDuplicate(output, suffix string) (string, error) {
 	if fileExists(output + suffix) {
 		var ok bool
@@ -186,9 +279,7 @@ func checkFileDuplicate(output, suffix string) (string, error) {
 }
 
 func fileExists(filename string) bool {
-	info, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return false
-	}
-	return !info.IsDir()
+	// Directories are included so that frame sequence directories aren't reused
+	_, err := os.Stat(filename)
+	return !os.IsNotExist(err)
 }
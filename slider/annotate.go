@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultTimeFormat is the default format used to draw image capture times.
+const DefaultTimeFormat = "2006-01-02 15:04 MST"
+
+// Corner is a corner of an image used to position overlays.
+type Corner int
+
+const (
+	// TopLeft is the upper-left-hand corner of the image.
+	TopLeft Corner = iota
+	// TopRight is the upper-right-hand corner of the image.
+	TopRight
+	// BottomLeft is the lower-left-hand corner of the image.
+	BottomLeft
+	// BottomRight is the lower-right-hand corner of the image.
+	BottomRight
+)
+
+// ParseCorner parses a corner name such as "top-left" or "bottom-right".
+func ParseCorner(s string) (Corner, error) {
+	switch strings.ToLower(s) {
+	case "top-left":
+		return TopLeft, nil
+	case "top-right":
+		return TopRight, nil
+	case "bottom-left":
+		return BottomLeft, nil
+	case "bottom-right":
+		return BottomRight, nil
+	default:
+		return TopLeft, fmt.Errorf("unknown position '%s': options are 'top-left', 'top-right', "+
+			"'bottom-left', and 'bottom-right'", s)
+	}
+}
+
+// position returns the upper-left-hand corner of an area of the size placed in the corner of the bounds.
+func (c Corner) position(bounds image.Rectangle, size image.Point, margin int) image.Point {
+	pt := image.Pt(bounds.Min.X+margin, bounds.Min.Y+margin)
+	if c == TopRight || c == BottomRight {
+		pt.X = bounds.Max.X - margin - size.X
+	}
+	if c == BottomLeft || c == BottomRight {
+		pt.Y = bounds.Max.Y - margin - size.Y
+	}
+	return pt
+}
+
+// AnnotationOptions are the options used to draw text labels onto each frame of an animation.
+type AnnotationOptions struct {
+	// Background is the color of the box drawn behind the text. No box is drawn if Background is nil.
+	Background color.Color
+	// Caption is custom text drawn below the other labels.
+	Caption string
+	// Color is the color of the text. White is used if Color is nil.
+	Color color.Color
+	// FontSize is the height of the text in pixels. If FontSize is zero the text is sized relative to the image.
+	FontSize float64
+	// Location is the time zone used to draw capture times. UTC is used if Location is nil.
+	Location *time.Location
+	// Position is the corner of the image to draw the labels in.
+	Position Corner
+	// ShowTime draws the image capture time.
+	ShowTime bool
+	// ShowTitle draws the satellite and product titles.
+	ShowTitle bool
+	// TimeFormat is the Go time layout used to draw capture times. DefaultTimeFormat is used if TimeFormat is empty.
+	TimeFormat string
+}
+
+// labelText returns the lines of text drawn onto the frame captured at the timestamp.
+func (a *AnnotationOptions) labelText(opts *LoopOptions, timestamp time.Time) string {
+	var lines []string
+	if a.ShowTitle {
+		lines = append(lines, fmt.Sprintf("%s - %s", opts.Satellite.SatelliteTitle, opts.Product.ProductTitle))
+	}
+	if a.ShowTime {
+		location := a.Location
+		if location == nil {
+			location = time.UTC
+		}
+		format := a.TimeFormat
+		if format == "" {
+			format = DefaultTimeFormat
+		}
+		lines = append(lines, timestamp.In(location).Format(format))
+	}
+	if a.Caption != "" {
+		lines = append(lines, a.Caption)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// captureTimeText returns the capture time formatted with the time zone and format of the annotation options if
+// they are set, or in UTC with DefaultTimeFormat otherwise.
+func captureTimeText(opts *LoopOptions, timestamp time.Time) string {
+	location, format := time.UTC, DefaultTimeFormat
+	if opts.Annotation != nil && opts.Annotation.Location != nil {
+		location = opts.Annotation.Location
+	}
+	if opts.Annotation != nil && opts.Annotation.TimeFormat != "" {
+		format = opts.Annotation.TimeFormat
+	}
+	return timestamp.In(location).Format(format)
+}
+
+// relativeFontSize is the font size for text sized relative to an image of the bounds.
+func relativeFontSize(bounds image.Rectangle) float64 {
+	return math.Max(12, math.Round(float64(bounds.Dy())/36))
+}
+
+// annotateImages draws the annotation labels onto each of the images captured at the corresponding times.
+func annotateImages(opts *LoopOptions, images []image.Image, times []time.Time) ([]image.Image, error) {
+	a := opts.Annotation
+	if a == nil {
+		return images, nil
+	}
+	if !a.ShowTitle && !a.ShowTime && a.Caption == "" {
+		return images, nil
+	}
+	fg := a.Color
+	if fg == nil {
+		fg = color.White
+	}
+	annotated := make([]image.Image, len(images))
+	for i, img := range images {
+		text := a.labelText(opts, times[i])
+		// Labels are kept off of the attribution footer
+		area := img.Bounds()
+		if opts.Footer != nil {
+			footer, err := opts.Footer.height(area.Dx())
+			if err != nil {
+				return nil, fmt.Errorf("unable to render footer: %w", err)
+			}
+			area.Max.Y -= footer
+		}
+		size := a.FontSize
+		if size <= 0 {
+			size = relativeFontSize(area)
+		}
+		mask, err := textMask(text, size)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render label: %w", err)
+		}
+		padding := int(math.Ceil(size / 4))
+		boxSize := mask.Bounds().Size().Add(image.Pt(2*padding, 2*padding))
+		canvas := imaging.Clone(img)
+		drawTextBox(canvas, mask, a.Position.position(area, boxSize, padding), padding, fg, a.Background)
+		annotated[i] = canvas
+	}
+	return annotated, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// coloredArea returns the smallest rectangle containing every pixel of the color.
+func coloredArea(img image.Image, c color.NRGBA) image.Rectangle {
+	nrgba := imaging.Clone(img)
+	var area image.Rectangle
+	for y := 0; y < nrgba.Bounds().Dy(); y++ {
+		for x := 0; x < nrgba.Bounds().Dx(); x++ {
+			if nrgba.NRGBAAt(x, y) == c {
+				area = area.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return area
+}
+
+func TestAnnotateImages(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	timestamp := time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC)
+	opts := &LoopOptions{
+		Annotation: &AnnotationOptions{Caption: "Hurricane Ida", Color: red, Position: BottomRight, ShowTime: true},
+		Footer:     &FooterOptions{},
+	}
+	images, err := decorateImages(opts, []image.Image{imaging.New(300, 200, color.Black)})
+	require.NoError(t, err)
+	footerTop := 200
+	require.Greater(t, images[0].Bounds().Dy(), footerTop)
+
+	annotated, err := annotateImages(opts, images, []time.Time{timestamp})
+	require.NoError(t, err)
+	label := coloredArea(annotated[0], red)
+	require.False(t, label.Empty(), "Label should be drawn")
+	assert.Greater(t, label.Min.X, 150, "Label should be in the right half")
+	assert.Greater(t, label.Min.Y, 100, "Label should be in the bottom half")
+	assert.LessOrEqual(t, label.Max.Y, footerTop, "Label should stay above the footer")
+
+	opts.Annotation.Position = TopLeft
+	annotated, err = annotateImages(opts, images, []time.Time{timestamp})
+	require.NoError(t, err)
+	label = coloredArea(annotated[0], red)
+	require.False(t, label.Empty())
+	assert.Less(t, label.Max.X, 150, "Label should be in the left half")
+	assert.Less(t, label.Max.Y, 100, "Label should be in the top half")
+
+	// Frames without any label text are left alone
+	opts.Annotation = &AnnotationOptions{Color: red}
+	annotated, err = annotateImages(opts, images, []time.Time{timestamp})
+	require.NoError(t, err)
+	assert.Equal(t, images[0], annotated[0])
+}
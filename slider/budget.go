@@ -0,0 +1,350 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/kettek/apng"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/gif"
+	"math"
+	"strings"
+	"time"
+)
+
+// Reduction is a way of reducing the file size of an animation.
+type Reduction int
+
+const (
+	// ReduceColors reduces the number of colors in the palette of each frame. This only applies to GIF animations.
+	ReduceColors Reduction = iota
+	// ReduceDimensions reduces the width and height of each frame.
+	ReduceDimensions
+	// ReduceFrames removes frames evenly from the animation. The first and last frames are always kept.
+	ReduceFrames
+)
+
+// The smallest values that reductions will reduce an animation to.
+const (
+	minBudgetColors    = 16
+	minBudgetDimension = 64
+	minBudgetFrames    = 2
+)
+
+// DefaultBudgetPriority is the order reductions are used in if no order is given.
+var DefaultBudgetPriority = []Reduction{ReduceColors, ReduceDimensions, ReduceFrames}
+
+// ParseReduction parses the name of a reduction. The options are "colors", "dimensions", and "frames".
+func ParseReduction(s string) (Reduction, error) {
+	switch strings.ToLower(s) {
+	case "colors":
+		return ReduceColors, nil
+	case "dimensions":
+		return ReduceDimensions, nil
+	case "frames":
+		return ReduceFrames, nil
+	default:
+		return ReduceColors, fmt.Errorf("unknown reduction '%s': options are 'colors', 'dimensions', and 'frames'", s)
+	}
+}
+
+// BudgetOptions are the options used to fit an animation within a maximum file size.
+type BudgetOptions struct {
+	// MaxBytes is the maximum size of the animation file in bytes.
+	MaxBytes int64
+	// Priority is the order that reductions are used in. Each reduction is used until it reaches its limit before
+	// the next one is used. Reductions that are not listed are not used. DefaultBudgetPriority is used if Priority
+	// is empty.
+	Priority []Reduction
+}
+
+// budgetState is the amount that an animation has been reduced by.
+type budgetState struct {
+	// colors is the number of palette colors or zero if the palette is not reduced.
+	colors int
+	// frames is the number of frames kept.
+	frames int
+	// scale is the factor the frame dimensions are scaled by.
+	scale float64
+}
+
+// kept returns the indexes of the n images that are kept after frames have been removed.
+func (s *budgetState) kept(n int) []int {
+	kept := make([]int, s.frames)
+	for i := range kept {
+		if s.frames > 1 {
+			kept[i] = int(math.Round(float64(i*(n-1)) / float64(s.frames-1)))
+		}
+	}
+	return kept
+}
+
+// apply returns the images after the frames have been removed and resized.
+func (s *budgetState) apply(images []image.Image) []image.Image {
+	frames := make([]image.Image, s.frames)
+	for i, j := range s.kept(len(images)) {
+		frames[i] = images[j]
+		if s.scale < 1 {
+			size := s.size(images[j].Bounds().Size())
+			frames[i] = imaging.Resize(images[j], size.X, size.Y, imaging.Lanczos)
+		}
+	}
+	return frames
+}
+
+// size is the size of a frame of the size after it has been resized.
+func (s *budgetState) size(size image.Point) image.Point {
+	return image.Pt(
+		int(math.Max(1, math.Round(float64(size.X)*s.scale))),
+		int(math.Max(1, math.Round(float64(size.Y)*s.scale))),
+	)
+}
+
+// reduce makes the next reduction in the priority order that hasn't reached its limit. The ratio is the maximum file
+// size divided by the current file size and is used to estimate how much to reduce by. False is returned if every
+// reduction has reached its limit.
+func (s *budgetState) reduce(priority []Reduction, size image.Point, ratio float64) bool {
+	estimate := math.Max(0.5, math.Min(0.9, ratio*0.95))
+	for _, r := range priority {
+		switch r {
+		case ReduceColors:
+			if s.colors > minBudgetColors {
+				s.colors /= 2
+				return true
+			}
+		case ReduceDimensions:
+			shortest := math.Min(float64(size.X), float64(size.Y))
+			scale := math.Max(s.scale*math.Sqrt(estimate), minBudgetDimension/shortest)
+			if scale < s.scale {
+				s.scale = scale
+				return true
+			}
+		case ReduceFrames:
+			if s.frames > minBudgetFrames {
+				frames := int(math.Max(minBudgetFrames, math.Floor(float64(s.frames)*estimate)))
+				if frames >= s.frames {
+					frames = s.frames - 1
+				}
+				s.frames = frames
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fitBudget encodes the images repeatedly, reducing them each time, until the encoded size is within the budget.
+// Colors is the starting number of palette colors or zero if the palette can't be reduced. Encode is called with
+// the reduced frames, the indexes of the images that were kept, and the number of palette colors and returns the
+// encoded size in bytes.
+func fitBudget(budget *BudgetOptions, images []image.Image, colors int,
+	encode func(frames []image.Image, kept []int, colors int) (int64, error)) error {
+	if len(images) == 0 {
+		return fmt.Errorf("no images to animate")
+	}
+	priority := budget.Priority
+	if len(priority) == 0 {
+		priority = DefaultBudgetPriority
+	}
+	original := budgetState{colors: colors, frames: len(images), scale: 1}
+	state := original
+	srcSize := images[0].Bounds().Size()
+	for {
+		size, err := encode(state.apply(images), state.kept(len(images)), state.colors)
+		if err != nil {
+			return err
+		}
+		log.Debug().Msgf("Animation with %d frames, %d colors, and %.2f scale is %d bytes", state.frames,
+			state.colors, state.scale, size)
+		if size <= budget.MaxBytes {
+			if state != original {
+				log.Warn().Msgf("Reduced the animation to %d bytes to fit within %d bytes: %s", size,
+					budget.MaxBytes, budgetChanges(original, state, srcSize))
+			}
+			return nil
+		}
+		if !state.reduce(priority, srcSize, float64(budget.MaxBytes)/float64(size)) {
+			return fmt.Errorf("unable to fit the animation within %d bytes: the smallest animation is %d bytes",
+				budget.MaxBytes, size)
+		}
+	}
+}
+
+// budgetChanges describes what was reduced between the original and reduced states.
+func budgetChanges(original, reduced budgetState, size image.Point) string {
+	var changes []string
+	if reduced.colors != original.colors {
+		changes = append(changes, fmt.Sprintf("colors %d -> %d", original.colors, reduced.colors))
+	}
+	if reduced.scale != original.scale {
+		resized := reduced.size(size)
+		changes = append(changes, fmt.Sprintf("dimensions %dx%d -> %dx%d", size.X, size.Y, resized.X, resized.Y))
+	}
+	if reduced.frames != original.frames {
+		changes = append(changes, fmt.Sprintf("frames %d -> %d", original.frames, reduced.frames))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// animateGIF animates the images into a GIF that fits within the budget if one is set. The times are the capture
+// times of the images. The encoded animation is also returned if it was encoded to check the budget.
+func animateGIF(opts *LoopOptions, images []image.Image, times []time.Time) (*gif.GIF, []byte, error) {
+	gifOpts := GIFOptions{}
+	if opts.GIF != nil {
+		gifOpts = *opts.GIF
+	}
+	animate := func(frames []image.Image, times []time.Time) (*gif.GIF, error) {
+		var err error
+		gifOpts.delays, err = loopDelays(opts, times)
+		if err != nil {
+			return nil, err
+		}
+		animation, err := AnimateGIFWithOptions(frames, opts.Speed, opts.Loop, &gifOpts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Timing != nil {
+			animation.LoopCount = gifLoopCount(opts.Timing.LoopCount)
+		}
+		return animation, nil
+	}
+	if opts.Budget == nil {
+		animation, err := animate(images, times)
+		return animation, nil, err
+	}
+	colors := gifOpts.NumColors
+	if colors == 0 {
+		colors = 256
+	}
+	var animation *gif.GIF
+	var encoded bytes.Buffer
+	err := fitBudget(opts.Budget, images, colors, func(frames []image.Image, kept []int, colors int) (int64, error) {
+		var err error
+		gifOpts.NumColors = colors
+		animation, err = animate(frames, keptTimes(times, kept))
+		if err != nil {
+			return 0, err
+		}
+		// The accepted animation is kept so that it doesn't need to be encoded again when it is saved
+		encoded.Reset()
+		err = gif.EncodeAll(&encoded, animation)
+		if err != nil {
+			return 0, fmt.Errorf("unable to encode GIF: %w", err)
+		}
+		return int64(encoded.Len()), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return animation, encoded.Bytes(), nil
+}
+
+// animatePNG animates the images into a PNG that fits within the budget if one is set. The times are the capture
+// times of the images. The encoded animation is also returned if it was encoded to check the budget.
+func animatePNG(opts *LoopOptions, images []image.Image, times []time.Time) (*apng.APNG, []byte, error) {
+	animate := func(frames []image.Image, times []time.Time) (*apng.APNG, error) {
+		delays, err := loopDelays(opts, times)
+		if err != nil {
+			return nil, err
+		}
+		animation, err := AnimatePNG(frames, opts.Speed, opts.Loop)
+		if err != nil {
+			return nil, err
+		}
+		for i, delay := range delays {
+			animation.Frames[i].DelayNumerator = uint16(delay)
+		}
+		if opts.Timing != nil {
+			animation.LoopCount = uint(opts.Timing.LoopCount)
+		}
+		return animation, nil
+	}
+	if opts.Budget == nil {
+		animation, err := animate(images, times)
+		return animation, nil, err
+	}
+	var animation *apng.APNG
+	var encoded bytes.Buffer
+	err := fitBudget(opts.Budget, images, 0, func(frames []image.Image, kept []int, _ int) (int64, error) {
+		var err error
+		animation, err = animate(frames, keptTimes(times, kept))
+		if err != nil {
+			return 0, err
+		}
+		encoded.Reset()
+		err = apng.Encode(&encoded, *animation)
+		if err != nil {
+			return 0, fmt.Errorf("unable to encode PNG: %w", err)
+		}
+		return int64(encoded.Len()), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return animation, encoded.Bytes(), nil
+}
+
+// animateWebP animates the images into a WebP that fits within the budget if one is set. The times are the capture
+// times of the images. The encoded animation is also returned if it was encoded to check the budget.
+func animateWebP(opts *LoopOptions, images []image.Image, times []time.Time) (*WebPAnimation, []byte, error) {
+	webpOpts := opts.WebP
+	if webpOpts == nil {
+		webpOpts = &WebPOptions{}
+	}
+	animate := func(frames []image.Image, times []time.Time) (*WebPAnimation, error) {
+		delays, err := loopDelays(opts, times)
+		if err != nil {
+			return nil, err
+		}
+		animation, err := AnimateWebP(frames, opts.Speed, opts.Loop, webpOpts)
+		if err != nil {
+			return nil, err
+		}
+		for i, delay := range delays {
+			animation.Frames[i].Delay = delay
+		}
+		if opts.Timing != nil {
+			animation.LoopCount = opts.Timing.LoopCount
+		}
+		return animation, nil
+	}
+	if opts.Budget == nil {
+		animation, err := animate(images, times)
+		return animation, nil, err
+	}
+	var animation *WebPAnimation
+	var encoded bytes.Buffer
+	err := fitBudget(opts.Budget, images, 0, func(frames []image.Image, kept []int, _ int) (int64, error) {
+		var err error
+		animation, err = animate(frames, keptTimes(times, kept))
+		if err != nil {
+			return 0, err
+		}
+		encoded.Reset()
+		err = EncodeWebP(&encoded, animation)
+		if err != nil {
+			return 0, fmt.Errorf("unable to encode WebP: %w", err)
+		}
+		return int64(encoded.Len()), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return animation, encoded.Bytes(), nil
+}
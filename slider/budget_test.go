@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/gif"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFitBudget(t *testing.T) {
+	images := make([]image.Image, 10)
+	for i := range images {
+		images[i] = imaging.New(400, 200, color.NRGBA{R: uint8(i), A: 255})
+	}
+	// The encoded size is one byte per pixel per frame plus one byte per color
+	encode := func(colors *int, frames *[]image.Image) func([]image.Image, []int, int) (int64, error) {
+		return func(f []image.Image, _ []int, c int) (int64, error) {
+			*colors, *frames = c, f
+			size := f[0].Bounds().Size()
+			return int64(size.X*size.Y*len(f) + c), nil
+		}
+	}
+
+	var colors int
+	var frames []image.Image
+	err := fitBudget(&BudgetOptions{MaxBytes: 800000 + 256}, images, 256, encode(&colors, &frames))
+	require.NoError(t, err)
+	assert.Equal(t, 256, colors, "Animations within the budget should not be reduced")
+	assert.Len(t, frames, 10)
+
+	err = fitBudget(&BudgetOptions{MaxBytes: 400000, Priority: []Reduction{ReduceFrames}}, images, 256,
+		encode(&colors, &frames))
+	require.NoError(t, err)
+	assert.Equal(t, 256, colors, "Only the listed reductions should be used")
+	assert.Equal(t, image.Pt(400, 200), frames[0].Bounds().Size())
+	assert.LessOrEqual(t, len(frames), 4)
+	assert.Equal(t, images[0], frames[0], "The first frame should be kept")
+	assert.Equal(t, images[9], frames[len(frames)-1], "The last frame should be kept")
+
+	err = fitBudget(&BudgetOptions{MaxBytes: 400000, Priority: []Reduction{ReduceColors, ReduceDimensions}}, images,
+		256, encode(&colors, &frames))
+	require.NoError(t, err)
+	assert.Equal(t, 16, colors, "Colors should be reduced first")
+	assert.Len(t, frames, 10)
+	assert.Less(t, frames[0].Bounds().Dx(), 400)
+
+	err = fitBudget(&BudgetOptions{MaxBytes: 1000, Priority: []Reduction{ReduceColors}}, images, 256,
+		encode(&colors, &frames))
+	assert.Error(t, err, "Budgets that can't be met should fail")
+}
+
+func TestAnimateGIFBudget(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	images := make([]image.Image, 6)
+	for i := range images {
+		img := image.NewNRGBA(image.Rect(0, 0, 120, 80))
+		rng.Read(img.Pix)
+		images[i] = img
+	}
+	opts := &LoopOptions{Budget: &BudgetOptions{MaxBytes: 60000}, Speed: 10}
+	animation, encoded, err := animateGIF(opts, images, make([]time.Time, len(images)))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(encoded), 60000)
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, animation))
+	assert.Equal(t, buf.Bytes(), encoded, "The encoded animation should be the animation that fits the budget")
+}
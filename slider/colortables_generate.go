@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// colortables_generate.go downloads the color table legend images of every product from SLIDER and writes them to
+// colortables.go so that legends can be attached without downloading them. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/colinmcintosh/slider-cli/slider"
+	"github.com/rs/zerolog/log"
+	"go/format"
+	"io/ioutil"
+	"net/http"
+	"sort"
+)
+
+const header = `// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by colortables_generate.go; DO NOT EDIT.
+
+package slider
+
+// bundledColorTables are the color table legend images from SLIDER keyed by color table name. They are used when a
+// color table can't be downloaded or read from the cache.
+var bundledColorTables = map[string][]byte{
+`
+
+func main() {
+	inventory, err := slider.GetProductInventory()
+	if err != nil {
+		log.Fatal().Msgf("Unable to load product inventory: %v", err)
+	}
+	names := map[string]bool{}
+	for _, satellite := range inventory.Satellites {
+		for _, product := range satellite.Products {
+			if product.ColorTableName != "" {
+				names[product.ColorTableName] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	for _, name := range sorted {
+		data, err := download(fmt.Sprintf(slider.ColorTableURI, name))
+		if err != nil {
+			log.Warn().Msgf("Unable to download color table '%s' -- it will not be bundled: %v", name, err)
+			continue
+		}
+		fmt.Fprintf(&buf, "\t%q: []byte(%q),\n", name, data)
+	}
+	buf.WriteString("}\n")
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal().Msgf("Unable to format color tables: %v", err)
+	}
+	err = ioutil.WriteFile("colortables.go", source, 0644)
+	if err != nil {
+		log.Fatal().Msgf("Unable to write color tables: %v", err)
+	}
+}
+
+// download returns the body of the file at the URI.
+func download(uri string) ([]byte, error) {
+	resp, err := http.Get(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unable to download file: %s: HTTP%d", uri, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,452 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCompareTimeGap is the largest difference between the capture times of frames shown together in a comparison
+// loop before a warning is logged.
+const maxCompareTimeGap = 30 * time.Minute
+
+// CompareLayout is the arrangement of the panels in a comparison loop.
+type CompareLayout int
+
+const (
+	// SideBySide shows two panels next to each other in one row.
+	SideBySide CompareLayout = iota
+	// Stacked shows two panels above each other in one column.
+	Stacked
+	// Grid shows four panels in two rows of two.
+	Grid
+)
+
+// ParseCompareLayout parses a comparison layout given as rows by columns, such as "1x2", "2x1", or "2x2".
+func ParseCompareLayout(s string) (CompareLayout, error) {
+	switch strings.ToLower(s) {
+	case "1x2":
+		return SideBySide, nil
+	case "2x1":
+		return Stacked, nil
+	case "2x2":
+		return Grid, nil
+	default:
+		return SideBySide, fmt.Errorf("unknown comparison layout '%s': options are '1x2', '2x1', and '2x2'", s)
+	}
+}
+
+// dims returns the number of rows and columns of panels in the layout.
+func (l CompareLayout) dims() (rows int, cols int) {
+	switch l {
+	case Stacked:
+		return 2, 1
+	case Grid:
+		return 2, 2
+	default:
+		return 1, 2
+	}
+}
+
+// Panels returns the number of panels in the layout.
+func (l CompareLayout) Panels() int {
+	rows, cols := l.dims()
+	return rows * cols
+}
+
+// CompareMode is the way the panels of a comparison loop are shown.
+type CompareMode int
+
+const (
+	// TileCompare tiles the panels next to each other in the layout.
+	TileCompare CompareMode = iota
+	// SwipeCompare shows the first panel over the second with a vertical divider that sweeps across the frame from
+	// left to right over the course of the animation, revealing the second panel to the left of the divider.
+	SwipeCompare
+	// FlickerCompare alternates between the first and second panel at each capture time.
+	FlickerCompare
+)
+
+// DefaultSwipeSteps is the number of divider positions shown for each capture time of a swipe comparison if no
+// number is given.
+const DefaultSwipeSteps = 4
+
+// ParseCompareMode parses a comparison mode name such as "tile", "swipe", or "flicker".
+func ParseCompareMode(s string) (CompareMode, error) {
+	switch strings.ToLower(s) {
+	case "tile":
+		return TileCompare, nil
+	case "swipe":
+		return SwipeCompare, nil
+	case "flicker":
+		return FlickerCompare, nil
+	default:
+		return TileCompare, fmt.Errorf("unknown comparison mode '%s': options are 'tile', 'swipe', and 'flicker'", s)
+	}
+}
+
+// ComparePanel is a single loop shown in a comparison loop. The loop's own satellite, sector, or product is used for
+// any of them that are nil.
+type ComparePanel struct {
+	// Product is the product shown in the panel.
+	Product *Product
+	// Satellite is the satellite shown in the panel. The sector and product must be available on the satellite.
+	Satellite *Satellite
+	// Sector is the sector shown in the panel.
+	Sector *Sector
+	// Title is drawn in the upper-left-hand corner of the panel. The satellite and product titles are drawn if Title
+	// is empty.
+	Title string
+}
+
+// CompareOptions are the options used to combine several loops into one comparison animation.
+type CompareOptions struct {
+	// Layout is the arrangement of the panels in TileCompare mode.
+	Layout CompareLayout
+	// Mode is the way the panels are shown.
+	Mode CompareMode
+	// Panels are the loops to compare. Tiled panels are filled in rows from the upper-left-hand corner and there
+	// must be exactly as many panels as the layout has room for. SwipeCompare and FlickerCompare require two panels
+	// of the same satellite and sector.
+	Panels []ComparePanel
+	// SwipeSteps is the number of divider positions shown for each capture time in SwipeCompare mode.
+	// DefaultSwipeSteps is used if SwipeSteps is zero.
+	SwipeSteps int
+}
+
+// overlay returns true if the panels are shown on top of each other, so that each capture time is shown in several
+// frames.
+func (c *CompareOptions) overlay() bool {
+	return c.Mode == SwipeCompare || c.Mode == FlickerCompare
+}
+
+// panelCount returns the number of panels required by the comparison.
+func (c *CompareOptions) panelCount() int {
+	if c.overlay() {
+		return 2
+	}
+	return c.Layout.Panels()
+}
+
+// panelOptions returns the loop options for the panel. The crop area and zoom level are resolved for the panel's
+// sector.
+func panelOptions(opts *LoopOptions, panel *ComparePanel) (*LoopOptions, error) {
+	panelOpts := *opts
+	panelOpts.Compare = nil
+	if panel.Satellite != nil {
+		panelOpts.Satellite = panel.Satellite
+	}
+	if panel.Sector != nil {
+		panelOpts.Sector = panel.Sector
+	}
+	if panel.Product != nil {
+		panelOpts.Product = panel.Product
+	}
+	if !panelOpts.Satellite.ValidSectorProduct(panelOpts.Sector, panelOpts.Product) {
+		return nil, fmt.Errorf("product '%s' is not available for sector '%s' on satellite '%s'",
+			panelOpts.Product.ID(), panelOpts.Sector.ID(), panelOpts.Satellite.ID())
+	}
+	err := prepareArea(&panelOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &panelOpts, nil
+}
+
+// overlayPanelOptions returns the loop options for a panel that is overlaid onto the loop. Only the product may
+// differ from the loop so that the panel is requested at the loop's zoom level and crop area.
+func overlayPanelOptions(opts *LoopOptions, panel *ComparePanel) (*LoopOptions, error) {
+	if (panel.Satellite != nil && panel.Satellite != opts.Satellite) ||
+		(panel.Sector != nil && panel.Sector != opts.Sector) {
+		return nil, fmt.Errorf("swipe and flicker comparisons require panels of the same satellite and sector")
+	}
+	panelOpts := *opts
+	panelOpts.Compare = nil
+	if panel.Product != nil {
+		panelOpts.Product = panel.Product
+	}
+	if !panelOpts.Satellite.ValidSectorProduct(panelOpts.Sector, panelOpts.Product) {
+		return nil, fmt.Errorf("product '%s' is not available for sector '%s' on satellite '%s'",
+			panelOpts.Product.ID(), panelOpts.Sector.ID(), panelOpts.Satellite.ID())
+	}
+	if maxLevel := panelOpts.Sector.MaxZoomLevel - panelOpts.Product.ZoomLevelAdjust; opts.zoom.Level > maxLevel {
+		return nil, fmt.Errorf("zoom level %d is greater than the product max of %d", opts.zoom.Level, maxLevel)
+	}
+	return &panelOpts, nil
+}
+
+// title is the text drawn onto the panel.
+func (p *ComparePanel) title(opts *LoopOptions) string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return fmt.Sprintf("%s - %s", opts.Satellite.SatelliteTitle, opts.Product.ProductTitle)
+}
+
+// sameImagery returns true if the panel shows the same imagery as the loop so the loop's capture times can be used.
+func (p *ComparePanel) sameImagery(opts *LoopOptions) bool {
+	return (p.Satellite == nil || p.Satellite == opts.Satellite) && (p.Sector == nil || p.Sector == opts.Sector) &&
+		(p.Product == nil || p.Product == opts.Product)
+}
+
+// compareImages downloads the frames of each comparison panel at the capture times nearest to the selected times
+// and combines them into the frames of the comparison. The display time of each frame is also returned.
+func compareImages(opts *LoopOptions, selectedTimes []time.Time) ([]image.Image, []time.Time, error) {
+	c := opts.Compare
+	if len(c.Panels) != c.panelCount() {
+		return nil, nil, fmt.Errorf("comparison requires %d panels but %d were given", c.panelCount(), len(c.Panels))
+	}
+	if c.SwipeSteps < 0 {
+		return nil, nil, fmt.Errorf("number of swipe steps can't be negative: %d", c.SwipeSteps)
+	}
+	panels := make([][]image.Image, len(c.Panels))
+	titles := make([]string, len(c.Panels))
+	for i := range c.Panels {
+		panel := &c.Panels[i]
+		var panelOpts *LoopOptions
+		var err error
+		if c.Mode == TileCompare {
+			panelOpts, err = panelOptions(opts, panel)
+		} else {
+			panelOpts, err = overlayPanelOptions(opts, panel)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to prepare panel %d: %w", i+1, err)
+		}
+		titles[i] = panel.title(panelOpts)
+		panelTimes := selectedTimes
+		if !panel.sameImagery(opts) {
+			panelTimes, err = panelCaptureTimes(panelOpts, selectedTimes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to get times for panel %d: %w", i+1, err)
+			}
+		}
+		panels[i], err = getImages(panelOpts, panelTimes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get images for panel %d: %w", i+1, err)
+		}
+	}
+	switch c.Mode {
+	case SwipeCompare:
+		steps := c.SwipeSteps
+		if steps == 0 {
+			steps = DefaultSwipeSteps
+		}
+		return swipeComparison(panels, titles, selectedTimes, steps)
+	case FlickerCompare:
+		return flickerComparison(panels, titles, selectedTimes)
+	case TileCompare:
+		images, err := tileComparison(c.Layout, panels, titles)
+		return images, selectedTimes, err
+	default:
+		return nil, nil, fmt.Errorf("unknown comparison mode: %v", c.Mode)
+	}
+}
+
+// panelCaptureTimes returns the capture times of the panel's imagery nearest to each of the selected times.
+func panelCaptureTimes(opts *LoopOptions, selectedTimes []time.Time) ([]time.Time, error) {
+	estimateCount := opts.NumberOfImages * opts.TimeStep * 5
+	latestTimes, err := LatestTimes(opts.Satellite, opts.Sector, opts.Product, estimateCount)
+	if err != nil {
+		return nil, err
+	}
+	available := make([]time.Time, len(latestTimes))
+	for i, t := range latestTimes {
+		available[i], err = time.Parse("20060102150405", strconv.Itoa(t))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse timestamp '%v': %v", t, err)
+		}
+	}
+	matched, err := nearestTimes(available, selectedTimes)
+	if err != nil {
+		return nil, err
+	}
+	for i, timestamp := range matched {
+		if gap := timestamp.Sub(selectedTimes[i]); gap > maxCompareTimeGap || gap < -maxCompareTimeGap {
+			log.Warn().Msgf("The nearest %s - %s image to %v was captured at %v.", opts.Satellite.SatelliteTitle,
+				opts.Product.ProductTitle, selectedTimes[i], timestamp)
+		}
+	}
+	return matched, nil
+}
+
+// nearestTimes returns the available time nearest to each of the target times. Ties use the earlier time.
+func nearestTimes(available []time.Time, targets []time.Time) ([]time.Time, error) {
+	if len(available) == 0 {
+		return nil, fmt.Errorf("no images are available")
+	}
+	sorted := make(timeSortable, len(available))
+	copy(sorted, available)
+	sort.Sort(sorted)
+	matched := make([]time.Time, len(targets))
+	for i, target := range targets {
+		j := sort.Search(len(sorted), func(j int) bool { return !sorted[j].Before(target) })
+		switch {
+		case j == len(sorted):
+			matched[i] = sorted[j-1]
+		case j == 0:
+			matched[i] = sorted[0]
+		case sorted[j].Sub(target) < target.Sub(sorted[j-1]):
+			matched[i] = sorted[j]
+		default:
+			matched[i] = sorted[j-1]
+		}
+	}
+	return matched, nil
+}
+
+// tileComparison tiles the frames of each panel into single frames in the layout. Every panel is given a cell the
+// size of the largest panel frame and is centered in its cell with its title drawn in the upper-left-hand corner.
+func tileComparison(layout CompareLayout, panels [][]image.Image, titles []string) ([]image.Image, error) {
+	var cell image.Point
+	for _, frames := range panels {
+		for _, img := range frames {
+			size := img.Bounds().Size()
+			if size.X > cell.X {
+				cell.X = size.X
+			}
+			if size.Y > cell.Y {
+				cell.Y = size.Y
+			}
+		}
+	}
+	masks, padding, err := titleMasks(titles, image.Rectangle{Max: cell})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := layout.dims()
+	tiled := make([]image.Image, len(panels[0]))
+	for i := range tiled {
+		canvas := imaging.New(cell.X*cols, cell.Y*rows, color.NRGBA{})
+		for p, frames := range panels {
+			origin := image.Pt(p%cols*cell.X, p/cols*cell.Y)
+			offset := cell.Sub(frames[i].Bounds().Size()).Div(2)
+			canvas = imaging.Paste(canvas, frames[i], origin.Add(offset))
+			drawPanelTitle(canvas, masks[p], image.Rectangle{Min: origin, Max: origin.Add(cell)}, TopLeft, padding)
+		}
+		tiled[i] = canvas
+	}
+	return tiled, nil
+}
+
+// swipeComparison combines the frames of two panels of the same size into frames with a vertical divider that sweeps
+// across the frame over the course of the animation. The first panel is shown to the right of the divider and the
+// second panel to the left. Each capture time is shown for the number of steps.
+func swipeComparison(panels [][]image.Image, titles []string, times []time.Time, steps int) ([]image.Image,
+	[]time.Time, error) {
+	err := checkOverlaySizes(panels)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := panels[0][0].Bounds()
+	masks, padding, err := titleMasks(titles, bounds)
+	if err != nil {
+		return nil, nil, err
+	}
+	divider := bounds.Dx() / 200
+	if divider < 2 {
+		divider = 2
+	}
+	total := len(panels[0]) * steps
+	frames := make([]image.Image, total)
+	frameTimes := make([]time.Time, total)
+	for k := range frames {
+		i := k / steps
+		x := bounds.Dx() / 2
+		if total > 1 {
+			x = int(math.Round(float64(bounds.Dx()*k) / float64(total-1)))
+		}
+		canvas := imaging.Clone(panels[0][i])
+		revealed := image.Rect(0, 0, x, bounds.Dy())
+		draw.Draw(canvas, revealed, panels[1][i], panels[1][i].Bounds().Min, draw.Src)
+		line := image.Rect(x-divider/2, 0, x-divider/2+divider, bounds.Dy()).Intersect(canvas.Bounds())
+		draw.Draw(canvas, line, image.NewUniform(color.White), image.Point{}, draw.Src)
+		drawPanelTitle(canvas, masks[1], canvas.Bounds(), TopLeft, padding)
+		drawPanelTitle(canvas, masks[0], canvas.Bounds(), TopRight, padding)
+		frames[k] = canvas
+		frameTimes[k] = times[i]
+	}
+	return frames, frameTimes, nil
+}
+
+// flickerComparison alternates between the frames of two panels of the same size at each capture time.
+func flickerComparison(panels [][]image.Image, titles []string, times []time.Time) ([]image.Image, []time.Time,
+	error) {
+	err := checkOverlaySizes(panels)
+	if err != nil {
+		return nil, nil, err
+	}
+	masks, padding, err := titleMasks(titles, panels[0][0].Bounds())
+	if err != nil {
+		return nil, nil, err
+	}
+	frames := make([]image.Image, 0, 2*len(times))
+	frameTimes := make([]time.Time, 0, 2*len(times))
+	for i := range times {
+		for p := range panels {
+			canvas := imaging.Clone(panels[p][i])
+			drawPanelTitle(canvas, masks[p], canvas.Bounds(), TopLeft, padding)
+			frames = append(frames, canvas)
+			frameTimes = append(frameTimes, times[i])
+		}
+	}
+	return frames, frameTimes, nil
+}
+
+// checkOverlaySizes returns an error if the frames of the panels aren't all the same size.
+func checkOverlaySizes(panels [][]image.Image) error {
+	size := panels[0][0].Bounds().Size()
+	for _, frames := range panels {
+		for _, img := range frames {
+			if img.Bounds().Size() != size {
+				return fmt.Errorf("panel frames must be the same size: %v and %v", size, img.Bounds().Size())
+			}
+		}
+	}
+	return nil
+}
+
+// titleMasks renders the panel titles at a size relative to a panel of the bounds. The padding around each title is
+// also returned.
+func titleMasks(titles []string, bounds image.Rectangle) ([]*image.Alpha, int, error) {
+	size := relativeFontSize(bounds)
+	masks := make([]*image.Alpha, len(titles))
+	for i, title := range titles {
+		mask, err := textMask(title, size)
+		if err != nil {
+			return nil, 0, fmt.Errorf("unable to render panel title: %w", err)
+		}
+		masks[i] = mask
+	}
+	return masks, int(math.Ceil(size / 4)), nil
+}
+
+// drawPanelTitle draws the rendered panel title in a box in the corner of the panel area.
+func drawPanelTitle(dst draw.Image, mask *image.Alpha, area image.Rectangle, corner Corner, padding int) {
+	boxSize := mask.Bounds().Size().Add(image.Pt(2*padding, 2*padding))
+	drawTextBox(dst, mask, corner.position(area, boxSize, padding), padding, color.White, color.NRGBA{A: 0x99})
+}
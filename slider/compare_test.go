@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestParseCompareLayout(t *testing.T) {
+	layout, err := ParseCompareLayout("2x1")
+	require.NoError(t, err)
+	assert.Equal(t, Stacked, layout)
+	rows, cols := layout.dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 1, cols)
+	assert.Equal(t, 4, Grid.Panels())
+
+	_, err = ParseCompareLayout("3x3")
+	assert.Error(t, err)
+}
+
+func TestNearestTimes(t *testing.T) {
+	start := time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC)
+	available := []time.Time{start.Add(10 * time.Minute), start, start.Add(20 * time.Minute)}
+	targets := []time.Time{start.Add(-time.Hour), start.Add(4 * time.Minute), start.Add(5 * time.Minute),
+		start.Add(16 * time.Minute), start.Add(time.Hour)}
+
+	matched, err := nearestTimes(available, targets)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start, start, start, start.Add(20 * time.Minute), start.Add(20 * time.Minute)},
+		matched, "Ties should use the earlier time")
+
+	_, err = nearestTimes(nil, targets)
+	assert.Error(t, err)
+}
+
+func TestPanelOptions(t *testing.T) {
+	satellite := testInventory(t).Satellites["goes-16"]
+	opts := &LoopOptions{
+		Satellite: satellite,
+		Sector:    satellite.Sectors["conus"],
+		Product:   satellite.Products["geocolor"],
+		ZoomLevel: 1,
+	}
+	panel := &ComparePanel{Product: satellite.Products["band-13"]}
+	panelOpts, err := panelOptions(opts, panel)
+	require.NoError(t, err)
+	assert.Equal(t, satellite.Products["band-13"], panelOpts.Product)
+	assert.Equal(t, 1, panelOpts.zoom.Level)
+	assert.False(t, panel.sameImagery(opts))
+	assert.True(t, (&ComparePanel{}).sameImagery(opts))
+	assert.Equal(t, "GOES-16 (East; 75.2W) - "+satellite.Products["band-13"].ProductTitle, panel.title(panelOpts))
+
+	other := testInventory(t).Satellites["goes-17"]
+	_, err = panelOptions(opts, &ComparePanel{Satellite: other})
+	assert.Error(t, err, "Sectors from another satellite should fail")
+}
+
+func TestTileComparison(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	panels := [][]image.Image{
+		{imaging.New(40, 30, red), imaging.New(40, 30, red)},
+		{imaging.New(20, 30, blue), imaging.New(20, 30, blue)},
+	}
+
+	tiled, err := tileComparison(SideBySide, panels, []string{"A", "B"})
+	require.NoError(t, err)
+	require.Len(t, tiled, 2)
+	assert.Equal(t, image.Rect(0, 0, 80, 30), tiled[0].Bounds())
+	assert.Equal(t, red, imaging.Clone(tiled[1]).NRGBAAt(39, 29))
+	assert.Equal(t, uint8(0), imaging.Clone(tiled[1]).NRGBAAt(45, 29).A, "Smaller panels should be centered")
+	assert.Equal(t, blue, imaging.Clone(tiled[1]).NRGBAAt(60, 29))
+
+	tiled, err = tileComparison(Stacked, panels, []string{"A", "B"})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 60), tiled[0].Bounds())
+	assert.Equal(t, blue, imaging.Clone(tiled[0]).NRGBAAt(20, 59))
+}
+
+func TestParseCompareMode(t *testing.T) {
+	mode, err := ParseCompareMode("Swipe")
+	require.NoError(t, err)
+	assert.Equal(t, SwipeCompare, mode)
+	_, err = ParseCompareMode("blink")
+	assert.Error(t, err)
+}
+
+func TestOverlayPanelOptions(t *testing.T) {
+	satellite := testInventory(t).Satellites["goes-16"]
+	crop := image.Rect(10, 20, 110, 220)
+	opts := &LoopOptions{
+		Satellite: satellite,
+		Sector:    satellite.Sectors["conus"],
+		Product:   satellite.Products["geocolor"],
+		crop:      &crop,
+		zoom:      satellite.ZoomLevels()[2],
+	}
+	panelOpts, err := overlayPanelOptions(opts, &ComparePanel{Product: satellite.Products["band-13"]})
+	require.NoError(t, err)
+	assert.Equal(t, opts.zoom, panelOpts.zoom, "Panels should use the same zoom level")
+	assert.Equal(t, opts.crop, panelOpts.crop, "Panels should use the same crop area")
+
+	_, err = overlayPanelOptions(opts, &ComparePanel{Sector: satellite.Sectors["full-disk"]})
+	assert.Error(t, err, "Panels of another sector should fail")
+}
+
+func TestSwipeComparison(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	start := time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(10 * time.Minute)}
+	panels := [][]image.Image{
+		{imaging.New(300, 200, red), imaging.New(300, 200, red)},
+		{imaging.New(300, 200, blue), imaging.New(300, 200, blue)},
+	}
+
+	frames, frameTimes, err := swipeComparison(panels, []string{"A", "B"}, times, 3)
+	require.NoError(t, err)
+	require.Len(t, frames, 6)
+	assert.Equal(t, []time.Time{times[0], times[0], times[0], times[1], times[1], times[1]}, frameTimes)
+	assert.Equal(t, red, imaging.Clone(frames[0]).NRGBAAt(150, 150), "The divider should start at the left")
+	assert.Equal(t, blue, imaging.Clone(frames[2]).NRGBAAt(100, 150))
+	assert.Equal(t, red, imaging.Clone(frames[2]).NRGBAAt(150, 150))
+	assert.Equal(t, blue, imaging.Clone(frames[5]).NRGBAAt(150, 150), "The divider should end at the right")
+
+	panels[1] = []image.Image{imaging.New(30, 20, blue), imaging.New(30, 20, blue)}
+	_, _, err = swipeComparison(panels, []string{"A", "B"}, times, 3)
+	assert.Error(t, err, "Panels of different sizes should fail")
+}
+
+func TestFlickerComparison(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	start := time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(10 * time.Minute)}
+	panels := [][]image.Image{
+		{imaging.New(300, 200, red), imaging.New(300, 200, red)},
+		{imaging.New(300, 200, blue), imaging.New(300, 200, blue)},
+	}
+
+	frames, frameTimes, err := flickerComparison(panels, []string{"A", "B"}, times)
+	require.NoError(t, err)
+	require.Len(t, frames, 4)
+	assert.Equal(t, []time.Time{times[0], times[0], times[1], times[1]}, frameTimes)
+	for i, want := range []color.NRGBA{red, blue, red, blue} {
+		assert.Equal(t, want, imaging.Clone(frames[i]).NRGBAAt(150, 150))
+	}
+}
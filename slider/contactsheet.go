@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"time"
+)
+
+// DefaultContactSheetColumns is the number of thumbnails in each row of a contact sheet if no number is given.
+const DefaultContactSheetColumns = 4
+
+// ContactSheetOptions are the options used to lay out the frames of a loop in a grid in a single image.
+type ContactSheetOptions struct {
+	// Background is the color of the sheet behind the thumbnails. White is used if Background is nil. Captions are
+	// drawn in black or white, whichever stands out more against the background.
+	Background color.Color
+	// Columns is the number of thumbnails in each row. DefaultContactSheetColumns is used if Columns is zero.
+	Columns int
+	// Every is the interval between the frames included in the sheet, starting from the first frame. Every frame is
+	// included if Every is zero.
+	Every int
+	// JPEG saves the sheet as a .jpg file. The sheet is saved as a .png file otherwise.
+	JPEG bool
+	// Quality is the JPEG quality from 1 to 100. DefaultJPEGQuality is used if Quality is zero.
+	Quality int
+	// Spacing is the space in pixels between the thumbnails and around the edges of the sheet.
+	Spacing int
+	// ThumbnailWidth is the width in pixels that each frame is resized to. Frames are not resized if
+	// ThumbnailWidth is zero.
+	ThumbnailWidth int
+}
+
+// contactSheet lays out every Nth image in a grid with the capture time of each image drawn below it. The times
+// are drawn with the time zone and format of the annotation options if they are set.
+func contactSheet(opts *LoopOptions, images []image.Image, times []time.Time) (image.Image, error) {
+	c := opts.ContactSheet
+	if c == nil {
+		c = &ContactSheetOptions{}
+	}
+	columns, every := c.Columns, c.Every
+	if columns == 0 {
+		columns = DefaultContactSheetColumns
+	}
+	if every == 0 {
+		every = 1
+	}
+	if columns < 0 || every < 0 || c.Spacing < 0 || c.ThumbnailWidth < 0 {
+		return nil, fmt.Errorf("contact sheet columns, interval, spacing, and thumbnail width can't be negative")
+	}
+
+	var thumbnails []image.Image
+	var captions []string
+	var cell image.Point
+	for i := 0; i < len(images); i += every {
+		thumbnail := images[i]
+		if c.ThumbnailWidth > 0 {
+			thumbnail = imaging.Resize(thumbnail, c.ThumbnailWidth, 0, imaging.Lanczos)
+		}
+		thumbnails = append(thumbnails, thumbnail)
+		captions = append(captions, captureTimeText(opts, times[i]))
+		size := thumbnail.Bounds().Size()
+		if size.X > cell.X {
+			cell.X = size.X
+		}
+		if size.Y > cell.Y {
+			cell.Y = size.Y
+		}
+	}
+	if len(thumbnails) == 0 {
+		return nil, fmt.Errorf("no frames to lay out")
+	}
+	if columns > len(thumbnails) {
+		columns = len(thumbnails)
+	}
+	rows := (len(thumbnails) + columns - 1) / columns
+	log.Debug().Msgf("Laying out %d frames in a %dx%d contact sheet", len(thumbnails), columns, rows)
+
+	bg := c.Background
+	if bg == nil {
+		bg = color.White
+	}
+	var fg color.Color = color.Black
+	if r, g, b, _ := bg.RGBA(); 0.299*float64(r)+0.587*float64(g)+0.114*float64(b) < 0x8000 {
+		fg = color.White
+	}
+	size := relativeFontSize(image.Rectangle{Max: cell})
+	padding := int(math.Ceil(size / 4))
+	masks := make([]*image.Alpha, len(captions))
+	var captionHeight int
+	for i, caption := range captions {
+		mask, err := textMask(caption, size)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render caption: %w", err)
+		}
+		masks[i] = mask
+		if h := mask.Bounds().Dy() + 2*padding; h > captionHeight {
+			captionHeight = h
+		}
+	}
+
+	pitch := cell.Add(image.Pt(c.Spacing, c.Spacing+captionHeight))
+	sheet := imaging.New(columns*pitch.X+c.Spacing, rows*pitch.Y+c.Spacing, bg)
+	for i, thumbnail := range thumbnails {
+		origin := image.Pt(i%columns*pitch.X+c.Spacing, i/columns*pitch.Y+c.Spacing)
+		bounds := thumbnail.Bounds()
+		offset := image.Pt((cell.X-bounds.Dx())/2, cell.Y-bounds.Dy())
+		draw.Draw(sheet, bounds.Sub(bounds.Min).Add(origin.Add(offset)), thumbnail, bounds.Min, draw.Over)
+		pt := origin.Add(image.Pt((cell.X-masks[i].Bounds().Dx())/2-padding, cell.Y))
+		drawTextBox(sheet, masks[i], pt, padding, fg, nil)
+	}
+	return sheet, nil
+}
+
+// SaveContactSheet encodes the contact sheet into a .png file, or a .jpg file if the options are set to use JPEG.
+// The extension will be added automatically. If a file with the same name exists an incrementing number will be
+// appended to the end of the file name.
+func SaveContactSheet(output string, sheet image.Image, opts *ContactSheetOptions) (string, error) {
+	quality := opts.Quality
+	if quality == 0 {
+		quality = DefaultJPEGQuality
+	}
+	if quality < 1 || quality > 100 {
+		return "", fmt.Errorf("JPEG quality must be between 1 and 100: %d", quality)
+	}
+	ext := ".png"
+	if opts.JPEG {
+		ext = ".jpg"
+	}
+	output, err := checkFileDuplicate(output, ext)
+	if err != nil {
+		return "", err
+	}
+	err = saveFrame(output+ext, sheet, opts.JPEG, quality)
+	if err != nil {
+		return "", fmt.Errorf("unable to save contact sheet: %w", err)
+	}
+	log.Debug().Msgf("Saved contact sheet to '%s'", output+ext)
+	return output + ext, nil
+}
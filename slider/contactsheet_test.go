@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestContactSheet(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	var images []image.Image
+	var times []time.Time
+	for i := 0; i < 7; i++ {
+		images = append(images, imaging.New(200, 100, red))
+		times = append(times, start.Add(time.Duration(i)*10*time.Minute))
+	}
+	opts := &LoopOptions{ContactSheet: &ContactSheetOptions{Columns: 2, Every: 2, Spacing: 10, ThumbnailWidth: 100}}
+
+	sheet, err := contactSheet(opts, images, times)
+	require.NoError(t, err)
+	// Frames 0, 2, 4, and 6 are laid out in 2 rows of 2 with captions below each thumbnail
+	size := sheet.Bounds().Size()
+	assert.Equal(t, 2*110+10, size.X)
+	assert.Equal(t, 0, (size.Y-10)%2, "Rows should be the same height")
+	caption := (size.Y-10)/2 - 10 - 50
+	assert.Greater(t, caption, 12)
+	nrgba := imaging.Clone(sheet)
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, nrgba.NRGBAAt(5, 5), "Background should be white")
+	assert.Equal(t, red, nrgba.NRGBAAt(120+50, 10+25))
+	assert.Equal(t, red, nrgba.NRGBAAt(120+50, 10+60+caption+25))
+
+	// Captions stand out against dark backgrounds
+	opts.ContactSheet = &ContactSheetOptions{Background: color.Black, Every: 10}
+	sheet, err = contactSheet(opts, images, times)
+	require.NoError(t, err)
+	assert.Equal(t, 200, sheet.Bounds().Dx(), "Sheets should be no wider than the thumbnails")
+	var brightest uint8
+	nrgba = imaging.Clone(sheet)
+	for x := 0; x < 200; x++ {
+		for y := 100; y < sheet.Bounds().Dy(); y++ {
+			if c := nrgba.NRGBAAt(x, y); c.G > brightest {
+				brightest = c.G
+			}
+		}
+	}
+	assert.Equal(t, uint8(255), brightest, "Captions should be white on a black background")
+
+	opts.ContactSheet.Columns = -1
+	_, err = contactSheet(opts, images, times)
+	assert.Error(t, err)
+}
+
+func TestSaveContactSheet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slider-sheet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sheet := imaging.New(30, 20, color.White)
+	output, err := SaveContactSheet(filepath.Join(dir, "loop"), sheet, &ContactSheetOptions{JPEG: true})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "loop.jpg"), output)
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 30, 20), img.Bounds())
+
+	output, err = SaveContactSheet(filepath.Join(dir, "loop"), sheet, &ContactSheetOptions{JPEG: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, filepath.Join(dir, "loop.jpg"), output, "Existing sheets shouldn't be overwritten")
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// DefaultAttribution is the default text drawn in the attribution footer.
+const DefaultAttribution = "RAMMB/CIRA SLIDER / NOAA"
+
+// DefaultWatermarkScale is the default width of a watermark as a fraction of the image width.
+const DefaultWatermarkScale = 0.15
+
+// WatermarkOptions are the options used to draw an image watermark onto each frame of an animation.
+type WatermarkOptions struct {
+	// Image is the watermark image. Transparency in the image is preserved.
+	Image image.Image
+	// Opacity is the opacity of the watermark from 0 (invisible) to 1 (fully opaque).
+	Opacity float64
+	// Position is the corner of the frame to draw the watermark in.
+	Position Corner
+	// Scale is the width of the watermark as a fraction of the frame width. DefaultWatermarkScale is used if Scale
+	// is zero.
+	Scale float64
+}
+
+// FooterOptions are the options used to attach a text attribution strip below each frame of an animation.
+type FooterOptions struct {
+	// Background is the color of the footer strip. Black is used if Background is nil.
+	Background color.Color
+	// Color is the color of the text. White is used if Color is nil.
+	Color color.Color
+	// FontSize is the height of the text in pixels. If FontSize is zero the text is sized relative to the frame width.
+	FontSize float64
+	// Text is the attribution text. DefaultAttribution is used if Text is empty.
+	Text string
+}
+
+// LoadWatermark opens a watermark image from a file. PNG, JPEG, GIF, TIFF, and BMP images are supported.
+func LoadWatermark(path string) (image.Image, error) {
+	img, err := imaging.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open watermark image: %w", err)
+	}
+	return img, nil
+}
+
+// fontSize is the font size of the footer text for frames of the width.
+func (f *FooterOptions) fontSize(width int) float64 {
+	if f.FontSize > 0 {
+		return f.FontSize
+	}
+	return math.Max(10, math.Round(float64(width)/60))
+}
+
+// padding is the space between the footer text and the edges of the footer strip.
+func (f *FooterOptions) padding(width int) int {
+	return int(math.Ceil(f.fontSize(width) / 3))
+}
+
+// height is the height of the footer strip attached to frames of the width.
+func (f *FooterOptions) height(width int) (int, error) {
+	mask, err := textMask(f.text(), f.fontSize(width))
+	if err != nil {
+		return 0, err
+	}
+	return mask.Bounds().Dy() + 2*f.padding(width), nil
+}
+
+func (f *FooterOptions) text() string {
+	if f.Text == "" {
+		return DefaultAttribution
+	}
+	return f.Text
+}
+
+// decorateFrame draws the watermark onto the frame and attaches the attribution footer below it.
+func decorateFrame(opts *LoopOptions, frame image.Image) (image.Image, error) {
+	if opts.Watermark != nil {
+		frame = drawWatermark(opts.Watermark, frame)
+	}
+	if opts.Footer != nil {
+		var err error
+		frame, err = attachFooter(opts.Footer, frame)
+		if err != nil {
+			return nil, fmt.Errorf("unable to attach footer: %w", err)
+		}
+	}
+	return frame, nil
+}
+
+// drawWatermark draws the watermark in the corner of the frame scaled relative to the width of the frame.
+func drawWatermark(w *WatermarkOptions, frame image.Image) image.Image {
+	bounds := frame.Bounds()
+	scale := w.Scale
+	if scale <= 0 {
+		scale = DefaultWatermarkScale
+	}
+	width := int(math.Round(float64(bounds.Dx()) * scale))
+	if width <= 0 || w.Opacity <= 0 {
+		return frame
+	}
+	mark := imaging.Resize(w.Image, width, 0, imaging.Lanczos)
+	margin := int(math.Round(float64(bounds.Dx()) / 50))
+
+	canvas := imaging.Clone(frame)
+	pt := w.Position.position(canvas.Bounds(), mark.Bounds().Size(), margin)
+	opacity := image.NewUniform(color.Alpha{A: uint8(math.Round(math.Min(1, w.Opacity) * 255))})
+	draw.DrawMask(canvas, mark.Bounds().Add(pt), mark, image.Point{}, opacity, image.Point{}, draw.Over)
+	return canvas
+}
+
+// attachFooter attaches a strip with the attribution text centered in it below the frame.
+func attachFooter(f *FooterOptions, frame image.Image) (image.Image, error) {
+	bounds := frame.Bounds()
+	size := f.fontSize(bounds.Dx())
+	mask, err := textMask(f.text(), size)
+	if err != nil {
+		return nil, err
+	}
+	padding := f.padding(bounds.Dx())
+	bg := f.Background
+	if bg == nil {
+		bg = color.Black
+	}
+	fg := f.Color
+	if fg == nil {
+		fg = color.White
+	}
+
+	strip := mask.Bounds().Dy() + 2*padding
+	canvas := imaging.New(bounds.Dx(), bounds.Dy()+strip, color.NRGBA{})
+	draw.Draw(canvas, bounds.Sub(bounds.Min), frame, bounds.Min, draw.Src)
+	draw.Draw(canvas, image.Rect(0, bounds.Dy(), bounds.Dx(), bounds.Dy()+strip), image.NewUniform(bg),
+		image.Point{}, draw.Src)
+	pt := image.Pt((bounds.Dx()-mask.Bounds().Dx())/2-padding, bounds.Dy())
+	drawTextBox(canvas, mask, pt, padding, fg, nil)
+	return canvas, nil
+}
+
+// decorateImages draws the watermark onto each image and attaches the attribution footer below it.
+func decorateImages(opts *LoopOptions, images []image.Image) ([]image.Image, error) {
+	if opts.Watermark == nil && opts.Footer == nil {
+		return images, nil
+	}
+	decorated := make([]image.Image, len(images))
+	for i, img := range images {
+		var err error
+		decorated[i], err = decorateFrame(opts, img)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decorated, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawWatermark(t *testing.T) {
+	frame := imaging.New(500, 300, color.NRGBA{A: 255})
+	mark := imaging.New(20, 10, color.NRGBA{R: 255, A: 255})
+	img := drawWatermark(&WatermarkOptions{Image: mark, Opacity: 0.5, Position: BottomRight, Scale: 0.2}, frame)
+
+	// The 100x50 watermark is drawn 10 pixels from the bottom-right-hand corner
+	nrgba := imaging.Clone(img)
+	assert.Equal(t, color.NRGBA{R: 128, A: 255}, nrgba.NRGBAAt(489, 289))
+	assert.Equal(t, color.NRGBA{R: 128, A: 255}, nrgba.NRGBAAt(390, 240))
+	assert.Equal(t, color.NRGBA{A: 255}, nrgba.NRGBAAt(389, 240))
+	assert.Equal(t, color.NRGBA{A: 255}, nrgba.NRGBAAt(490, 290))
+	assert.Equal(t, color.NRGBA{A: 255}, frame.NRGBAAt(489, 289), "Original frame should not be modified")
+}
+
+func TestAttachFooter(t *testing.T) {
+	frame := imaging.New(600, 400, color.NRGBA{B: 255, A: 255})
+	footer := &FooterOptions{}
+	img, err := attachFooter(footer, frame)
+	require.NoError(t, err)
+	h, err := footer.height(600)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(600, 400+h), img.Bounds().Size())
+	assert.Equal(t, color.NRGBA{B: 255, A: 255}, imaging.Clone(img).NRGBAAt(0, 399))
+	assert.Equal(t, color.NRGBA{A: 255}, imaging.Clone(img).NRGBAAt(0, 400))
+}
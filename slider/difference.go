@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultDifferenceRange is the brightness difference at which the difference color map saturates if no range is
+// given.
+const DefaultDifferenceRange = 0.25
+
+// differenceColors are the colors of a diverging red-blue color map from the largest decrease in brightness to the
+// largest increase.
+var differenceColors = []color.NRGBA{
+	{R: 5, G: 48, B: 97, A: 255},
+	{R: 67, G: 147, B: 195, A: 255},
+	{R: 247, G: 247, B: 247, A: 255},
+	{R: 214, G: 96, B: 77, A: 255},
+	{R: 103, G: 0, B: 31, A: 255},
+}
+
+// DifferenceMode is the frame that each frame is compared with in a difference loop.
+type DifferenceMode int
+
+const (
+	// ConsecutiveDifference compares each frame with the previous frame. The loop has one less frame than the
+	// number of images.
+	ConsecutiveDifference DifferenceMode = iota
+	// ReferenceDifference compares each frame with a single reference frame.
+	ReferenceDifference
+)
+
+// ParseDifferenceMode parses a difference mode name such as "consecutive" or "reference".
+func ParseDifferenceMode(s string) (DifferenceMode, error) {
+	switch strings.ToLower(s) {
+	case "consecutive":
+		return ConsecutiveDifference, nil
+	case "reference":
+		return ReferenceDifference, nil
+	default:
+		return ConsecutiveDifference, fmt.Errorf("unknown difference mode '%s': options are 'consecutive' and "+
+			"'reference'", s)
+	}
+}
+
+// DifferenceOptions are the options used to replace the imagery with the change in brightness of each pixel
+// between frames. Increases in brightness are drawn in red and decreases in blue.
+type DifferenceOptions struct {
+	// Mode is the frame that each frame is compared with.
+	Mode DifferenceMode
+	// Range is the change in brightness from 0 to 1 at which the color map saturates. DefaultDifferenceRange is used
+	// if Range is zero.
+	Range float64
+	// Reference is the index of the reference frame in ReferenceDifference mode, starting from the earliest frame.
+	Reference int
+	// Threshold is the smallest change in brightness from 0 to 1 that is highlighted. Smaller changes are drawn in
+	// the neutral color in the middle of the color map.
+	Threshold float64
+}
+
+// differenceImages replaces the images captured at the times with the difference images. The capture times of the
+// difference images are also returned.
+func differenceImages(opts *LoopOptions, images []image.Image, times []time.Time) ([]image.Image, []time.Time,
+	error) {
+	d := opts.Difference
+	if d == nil {
+		return images, times, nil
+	}
+	scale := d.Range
+	if scale == 0 {
+		scale = DefaultDifferenceRange
+	}
+	if scale < 0 || scale > 1 {
+		return nil, nil, fmt.Errorf("difference range must be greater than 0 and at most 1: %v", d.Range)
+	}
+	if d.Threshold < 0 || d.Threshold >= 1 {
+		return nil, nil, fmt.Errorf("difference threshold must be at least 0 and less than 1: %v", d.Threshold)
+	}
+	for _, img := range images[1:] {
+		if img.Bounds().Size() != images[0].Bounds().Size() {
+			return nil, nil, fmt.Errorf("images must be the same size to difference: %v and %v",
+				images[0].Bounds().Size(), img.Bounds().Size())
+		}
+	}
+	switch d.Mode {
+	case ConsecutiveDifference:
+		if len(images) < 2 {
+			return nil, nil, fmt.Errorf("at least 2 images are required for consecutive differences")
+		}
+		diffs := make([]image.Image, len(images)-1)
+		for i := range diffs {
+			diffs[i] = differenceImage(images[i], images[i+1], scale, d.Threshold)
+		}
+		return diffs, times[1:], nil
+	case ReferenceDifference:
+		if d.Reference < 0 || d.Reference >= len(images) {
+			return nil, nil, fmt.Errorf("reference frame %d is outside of the %d frames", d.Reference, len(images))
+		}
+		diffs := make([]image.Image, len(images))
+		for i, img := range images {
+			diffs[i] = differenceImage(images[d.Reference], img, scale, d.Threshold)
+		}
+		return diffs, times, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown difference mode: %v", d.Mode)
+	}
+}
+
+// differenceImage draws the change in brightness of each pixel from the first image to the second image. Changes
+// of the scale are drawn with the end colors of the color map. Pixels that are transparent in either image are
+// transparent.
+func differenceImage(from, to image.Image, scale, threshold float64) *image.NRGBA {
+	a, b := imaging.Clone(from), imaging.Clone(to)
+	diff := image.NewNRGBA(a.Bounds())
+	for y := 0; y < a.Rect.Dy(); y++ {
+		for x := 0; x < a.Rect.Dx(); x++ {
+			i := y*a.Stride + x*4
+			if a.Pix[i+3] == 0 || b.Pix[i+3] == 0 {
+				continue
+			}
+			change := luminance(b.Pix[i:i+3]) - luminance(a.Pix[i:i+3])
+			if math.Abs(change) < threshold {
+				change = 0
+			}
+			c := divergingColor(change / scale)
+			diff.Pix[i], diff.Pix[i+1], diff.Pix[i+2], diff.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+	return diff
+}
+
+// luminance is the brightness from 0 to 1 of the RGB pixel.
+func luminance(pix []uint8) float64 {
+	return (0.299*float64(pix[0]) + 0.587*float64(pix[1]) + 0.114*float64(pix[2])) / 255
+}
+
+// divergingColor is the color of the value from -1 to 1 in the difference color map. Values outside of the range
+// are clamped.
+func divergingColor(v float64) color.NRGBA {
+	v = math.Max(-1, math.Min(1, v))
+	pos := (v + 1) / 2 * float64(len(differenceColors)-1)
+	i := int(pos)
+	if i >= len(differenceColors)-1 {
+		return differenceColors[len(differenceColors)-1]
+	}
+	t := pos - float64(i)
+	c0, c1 := differenceColors[i], differenceColors[i+1]
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.NRGBA{R: mix(c0.R, c1.R), G: mix(c0.G, c1.G), B: mix(c0.B, c1.B), A: 255}
+}
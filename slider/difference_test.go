@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestParseDifferenceMode(t *testing.T) {
+	mode, err := ParseDifferenceMode("Reference")
+	require.NoError(t, err)
+	assert.Equal(t, ReferenceDifference, mode)
+	_, err = ParseDifferenceMode("previous")
+	assert.Error(t, err)
+}
+
+func TestDivergingColor(t *testing.T) {
+	assert.Equal(t, differenceColors[2], divergingColor(0))
+	assert.Equal(t, differenceColors[0], divergingColor(-3), "Values should be clamped")
+	assert.Equal(t, differenceColors[4], divergingColor(1))
+	assert.Equal(t, color.NRGBA{R: 157, G: 197, B: 221, A: 255}, divergingColor(-0.25))
+}
+
+func TestDifferenceImages(t *testing.T) {
+	gray := func(v uint8) image.Image {
+		return imaging.New(4, 2, color.NRGBA{R: v, G: v, B: v, A: 255})
+	}
+	start := time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(10 * time.Minute), start.Add(20 * time.Minute)}
+	images := []image.Image{gray(100), gray(200), gray(190)}
+	opts := &LoopOptions{Difference: &DifferenceOptions{Mode: ConsecutiveDifference, Range: 0.25, Threshold: 0.1}}
+
+	diffs, diffTimes, err := differenceImages(opts, images, times)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, times[1:], diffTimes)
+	assert.Equal(t, differenceColors[4], imaging.Clone(diffs[0]).NRGBAAt(0, 0), "Brighter pixels should be red")
+	assert.Equal(t, differenceColors[2], imaging.Clone(diffs[1]).NRGBAAt(0, 0),
+		"Changes below the threshold should be neutral")
+
+	opts.Difference = &DifferenceOptions{Mode: ReferenceDifference, Reference: 1}
+	diffs, diffTimes, err = differenceImages(opts, images, times)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	assert.Equal(t, times, diffTimes)
+	assert.Equal(t, differenceColors[0], imaging.Clone(diffs[0]).NRGBAAt(0, 0), "Darker pixels should be blue")
+	assert.Equal(t, differenceColors[2], imaging.Clone(diffs[1]).NRGBAAt(0, 0))
+
+	opts.Difference.Reference = 3
+	_, _, err = differenceImages(opts, images, times)
+	assert.Error(t, err, "Reference frame outside of the loop should fail")
+}
+
+func TestDifferenceImageTransparency(t *testing.T) {
+	from := imaging.New(2, 1, color.NRGBA{R: 50, G: 50, B: 50, A: 255})
+	to := imaging.Clone(from)
+	to.SetNRGBA(1, 0, color.NRGBA{})
+
+	diff := differenceImage(from, to, 1, 0)
+	assert.Equal(t, differenceColors[2], diff.NRGBAAt(0, 0))
+	assert.Equal(t, uint8(0), diff.NRGBAAt(1, 0).A, "Transparent pixels should stay transparent")
+}
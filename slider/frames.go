@@ -0,0 +1,186 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// DefaultJPEGQuality is the quality of JPEG frames if no quality is given.
+const DefaultJPEGQuality = 90
+
+// FrameManifestName is the name of the manifest file saved with the frames.
+const FrameManifestName = "manifest.json"
+
+// FramesOptions are the options used to save each frame as a separate image.
+type FramesOptions struct {
+	// JPEG saves the frames as .jpg files. Frames are saved as .png files otherwise.
+	JPEG bool
+	// Quality is the JPEG quality from 1 to 100. DefaultJPEGQuality is used if Quality is zero.
+	Quality int
+}
+
+// FrameManifest describes each frame saved in a frame sequence.
+type FrameManifest struct {
+	Frames []ManifestFrame `json:"frames"`
+}
+
+// ManifestFrame describes a single frame of a frame sequence.
+type ManifestFrame struct {
+	// File is the name of the frame's image file in the frame sequence directory.
+	File string `json:"file"`
+	// Time is the capture time of the frame.
+	Time      time.Time `json:"time"`
+	Satellite string    `json:"satellite"`
+	Sector    string    `json:"sector"`
+	Product   string    `json:"product"`
+	Zoom      int       `json:"zoom"`
+	// Crop is the area of the sector image at the zoom level that the frame was cropped to. Crop is nil if the frame
+	// wasn't cropped.
+	Crop *ManifestCrop `json:"crop"`
+	// Synthetic is true if the frame was interpolated between captured images. The time of a synthetic frame is
+	// interpolated between the capture times of the images.
+	Synthetic bool `json:"synthetic"`
+}
+
+// ManifestCrop is a crop area in pixels.
+type ManifestCrop struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// frameManifest creates the manifest for frames at the times. Synthetic is whether each frame was interpolated, or nil
+// if no frames were interpolated.
+func frameManifest(opts *LoopOptions, times []time.Time, synthetic []bool) (*FrameManifest, error) {
+	manifest := &FrameManifest{Frames: make([]ManifestFrame, len(times))}
+	for i, timestamp := range times {
+		frame := ManifestFrame{
+			Time:      timestamp.UTC(),
+			Satellite: opts.Satellite.ID(),
+			Sector:    opts.Sector.ID(),
+			Product:   opts.Product.ID(),
+			Zoom:      opts.zoom.Level,
+			Synthetic: synthetic != nil && synthetic[i],
+		}
+		crop, err := frameCrop(opts, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if crop != nil {
+			// Crop areas are clipped to the sector image
+			clipped := crop.Intersect(image.Rect(0, 0, opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom)))
+			frame.Crop = &ManifestCrop{X: clipped.Min.X, Y: clipped.Min.Y, Width: clipped.Dx(), Height: clipped.Dy()}
+		}
+		manifest.Frames[i] = frame
+	}
+	return manifest, nil
+}
+
+// SaveFrames saves each image as a numbered .png or .jpg file in a new directory along with a manifest.json file
+// describing each frame. The file names of the frames are set in the manifest. If a directory with the same name
+// exists an incrementing number will be appended to the end of the directory name.
+func SaveFrames(output string, images []image.Image, manifest *FrameManifest, opts *FramesOptions) (string, error) {
+	if len(images) != len(manifest.Frames) {
+		return "", fmt.Errorf("manifest has %d frames but there are %d images", len(manifest.Frames),
+			len(images))
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = DefaultJPEGQuality
+	}
+	if quality < 1 || quality > 100 {
+		return "", fmt.Errorf("JPEG quality must be between 1 and 100: %d", quality)
+	}
+	output, err := checkFileDuplicate(output, "")
+	if err != nil {
+		return "", err
+	}
+	err = os.Mkdir(output, 0750)
+	if err != nil {
+		return "", fmt.Errorf("unable to create frame directory: %w", err)
+	}
+	// Frame numbers are padded so that the files sort in order
+	digits := len(strconv.Itoa(len(images) - 1))
+	if digits < 3 {
+		digits = 3
+	}
+	for i, img := range images {
+		name := fmt.Sprintf("frame_%0*d", digits, i)
+		if opts.JPEG {
+			name += ".jpg"
+		} else {
+			name += ".png"
+		}
+		err = saveFrame(path.Join(output, name), img, opts.JPEG, quality)
+		if err != nil {
+			return "", fmt.Errorf("unable to save frame %d: %w", i, err)
+		}
+		manifest.Frames[i].File = name
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("unable to encode manifest: %w", err)
+	}
+	err = ioutil.WriteFile(path.Join(output, FrameManifestName), append(data, '\n'), 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to write manifest: %w", err)
+	}
+	log.Debug().Msgf("Saved %d frames to '%s'", len(images), output)
+	return output, nil
+}
+
+func saveFrame(name string, img image.Image, isJPEG bool, quality int) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %w", err)
+	}
+	err = encodeFrame(f, img, isJPEG, quality)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close file: %w", err)
+	}
+	return nil
+}
+
+func encodeFrame(w io.Writer, img image.Image, isJPEG bool, quality int) error {
+	var err error
+	if isJPEG {
+		err = jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(w, img)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveFrames(t *testing.T) {
+	satellite := testInventory(t).Satellites["goes-16"]
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	crop := image.Rect(1300, 100, 1500, 200)
+	opts := &LoopOptions{
+		Satellite: satellite,
+		Sector:    satellite.Sectors["full-disk"],
+		Product:   satellite.Products["geocolor"],
+		crop:      &crop,
+		zoom:      &Zoom{Level: 1},
+	}
+	times := []time.Time{start, start.Add(10 * time.Minute)}
+	manifest, err := frameManifest(opts, times, nil)
+	require.NoError(t, err)
+	require.Len(t, manifest.Frames, 2)
+	assert.Equal(t, ManifestFrame{
+		Time:      start,
+		Satellite: "goes-16",
+		Sector:    "full-disk",
+		Product:   "geocolor",
+		Zoom:      1,
+		Crop:      &ManifestCrop{X: 1300, Y: 100, Width: 56, Height: 100},
+	}, manifest.Frames[0], "Crop area should be clipped to the sector image")
+
+	synthetic, err := frameManifest(opts, times, []bool{false, true})
+	require.NoError(t, err)
+	assert.False(t, synthetic.Frames[0].Synthetic)
+	assert.True(t, synthetic.Frames[1].Synthetic, "Interpolated frames should be marked")
+
+	dir, err := ioutil.TempDir("", "slider-frames")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	images := []image.Image{image.NewRGBA(image.Rect(0, 0, 56, 100)), image.NewRGBA(image.Rect(0, 0, 56, 100))}
+	output, err := SaveFrames(filepath.Join(dir, "loop"), images, manifest, &FramesOptions{JPEG: true})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "loop"), output)
+
+	data, err := ioutil.ReadFile(filepath.Join(output, FrameManifestName))
+	require.NoError(t, err)
+	var saved FrameManifest
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, manifest, &saved)
+	assert.Equal(t, "frame_001.jpg", saved.Frames[1].File)
+	assert.Contains(t, string(data), `"time": "2021-08-29T00:10:00Z"`)
+	f, err := os.Open(filepath.Join(output, saved.Frames[1].File))
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 56, 100), img.Bounds())
+
+	output, err = SaveFrames(filepath.Join(dir, "loop"), images, manifest, &FramesOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "loop_01"), output, "Existing directories should not be reused")
+	assert.FileExists(t, filepath.Join(output, "frame_000.png"))
+
+	_, err = SaveFrames(filepath.Join(dir, "loop"), images[:1], manifest, &FramesOptions{})
+	assert.Error(t, err, "Mismatched manifest should fail")
+}
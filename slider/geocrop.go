@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+)
+
+// boundingBoxSamples is the number of points sampled along each edge of a bounding box when projecting it.
+const boundingBoxSamples = 32
+
+// LatLon is a geographic position in degrees.
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// BoundingBox is a geographic area in degrees. MinLon may be greater than MaxLon for boxes that cross the
+// antimeridian.
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Validate returns an error if the bounding box coordinates are out of range.
+func (b *BoundingBox) Validate() error {
+	if b.MinLat < -90 || b.MaxLat > 90 {
+		return fmt.Errorf("latitudes must be between -90 and 90: %v, %v", b.MinLat, b.MaxLat)
+	}
+	if b.MinLon < -180 || b.MinLon > 180 || b.MaxLon < -180 || b.MaxLon > 180 {
+		return fmt.Errorf("longitudes must be between -180 and 180: %v, %v", b.MinLon, b.MaxLon)
+	}
+	if b.MinLat >= b.MaxLat {
+		return fmt.Errorf("minimum latitude %v must be less than maximum latitude %v", b.MinLat, b.MaxLat)
+	}
+	if b.MinLon == b.MaxLon {
+		return fmt.Errorf("minimum and maximum longitude must not be equal: %v", b.MinLon)
+	}
+	return nil
+}
+
+// BoundingBoxCrop returns the pixel area in the sector image at the zoom level which contains the bounding box.
+// An error is returned if any part of the bounding box isn't visible from the satellite.
+func (p *Projection) BoundingBoxCrop(box *BoundingBox, zoom *Zoom) (image.Rectangle, error) {
+	err := box.Validate()
+	if err != nil {
+		return image.Rectangle{}, fmt.Errorf("invalid bounding box: %w", err)
+	}
+	maxLon := box.MaxLon
+	if maxLon < box.MinLon {
+		maxLon += 360
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i <= boundingBoxSamples; i++ {
+		f := float64(i) / boundingBoxSamples
+		lon := box.MinLon + f*(maxLon-box.MinLon)
+		lat := box.MinLat + f*(box.MaxLat-box.MinLat)
+		edges := []LatLon{
+			{Lat: box.MinLat, Lon: lon},
+			{Lat: box.MaxLat, Lon: lon},
+			{Lat: lat, Lon: box.MinLon},
+			{Lat: lat, Lon: maxLon},
+		}
+		for _, point := range edges {
+			x, y, ok := p.LatLonToPixel(point.Lat, point.Lon, zoom)
+			if !ok {
+				return image.Rectangle{}, fmt.Errorf("bounding box point %.2f,%.2f is not visible from the "+
+					"satellite", point.Lat, normalizeLon(point.Lon))
+			}
+			minX, minY = math.Min(minX, x), math.Min(minY, y)
+			maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+		}
+	}
+
+	crop := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	bounds := image.Rect(0, 0, p.sector.XSize(zoom), p.sector.YSize(zoom))
+	if !crop.In(bounds) {
+		return image.Rectangle{}, fmt.Errorf("bounding box %v extends outside of the %dx%d sector image",
+			crop, bounds.Dx(), bounds.Dy())
+	}
+	return crop, nil
+}
+
+// CenterCrop returns the pixel area in the sector image at the zoom level of the specified size centered on the
+// provided point. An error is returned if the center isn't visible from the satellite or if the area extends
+// outside of the sector image.
+func (p *Projection) CenterCrop(center LatLon, size image.Point, zoom *Zoom) (image.Rectangle, error) {
+	crop, err := p.centerRect(center, size, zoom)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	bounds := image.Rect(0, 0, p.sector.XSize(zoom), p.sector.YSize(zoom))
+	if !crop.In(bounds) {
+		return image.Rectangle{}, fmt.Errorf("crop area %v centered on %.2f,%.2f extends outside of the %dx%d "+
+			"sector image", crop, center.Lat, center.Lon, bounds.Dx(), bounds.Dy())
+	}
+	return crop, nil
+}
+
+func (p *Projection) centerRect(center LatLon, size image.Point, zoom *Zoom) (image.Rectangle, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		return image.Rectangle{}, fmt.Errorf("crop size must be greater than zero: %dx%d", size.X, size.Y)
+	}
+	if center.Lat < -90 || center.Lat > 90 {
+		return image.Rectangle{}, fmt.Errorf("latitude must be between -90 and 90: %v", center.Lat)
+	}
+	x, y, ok := p.LatLonToPixel(center.Lat, center.Lon, zoom)
+	if !ok {
+		return image.Rectangle{}, fmt.Errorf("center point %.2f,%.2f is not visible from the satellite",
+			center.Lat, center.Lon)
+	}
+	minX := int(math.Round(x)) - size.X/2
+	minY := int(math.Round(y)) - size.Y/2
+	return image.Rect(minX, minY, minX+size.X, minY+size.Y), nil
+}
+
+// resolveCrop determines the pixel area to crop the animation to from either Crop or the geographic crop options.
+func resolveCrop(opts *LoopOptions) error {
+	opts.crop = opts.Crop
+	opts.centerSize = opts.CenterSize
+	geoOptions := 0
+	for _, set := range []bool{opts.BoundingBox != nil, opts.Center != nil, opts.Track != nil} {
+		if set {
+			geoOptions++
+		}
+	}
+	if geoOptions == 0 {
+		return nil
+	}
+	if geoOptions > 1 {
+		return fmt.Errorf("only one of a bounding box, center point, or track may be used to crop")
+	}
+	if opts.Crop != nil {
+		return fmt.Errorf("pixel crop area can't be used with a bounding box, center point, or track")
+	}
+	projection, err := opts.Sector.Projection()
+	if err != nil {
+		return err
+	}
+	opts.projection = projection
+
+	var crop image.Rectangle
+	switch {
+	case opts.BoundingBox != nil:
+		crop, err = projection.BoundingBoxCrop(opts.BoundingBox, opts.zoom)
+	case opts.Center != nil:
+		crop, err = projection.CenterCrop(*opts.Center, opts.centerSize, opts.zoom)
+	case opts.Track != nil:
+		// The crop area is determined for each frame
+		if opts.CenterSize.X > opts.Sector.XSize(opts.zoom) || opts.CenterSize.Y > opts.Sector.YSize(opts.zoom) {
+			return fmt.Errorf("track crop size %dx%d is larger than the %dx%d sector image", opts.CenterSize.X,
+				opts.CenterSize.Y, opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom))
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	opts.crop = &crop
+	return nil
+}
+
+// frameCrop is the crop area for the frame captured at the timestamp. This is nil if the frame isn't cropped.
+func frameCrop(opts *LoopOptions, timestamp time.Time) (*image.Rectangle, error) {
+	if opts.Track != nil {
+		return trackCrop(opts, timestamp)
+	}
+	return opts.crop, nil
+}
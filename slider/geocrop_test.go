@@ -0,0 +1,59 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"testing"
+)
+
+func TestBoundingBoxCrop(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["full-disk"]
+	p, err := sector.Projection()
+	require.NoError(t, err)
+	zoom := &Zoom{Level: 3}
+
+	crop, err := p.BoundingBoxCrop(&BoundingBox{MinLon: -88, MinLat: 24, MaxLon: -79, MaxLat: 31}, zoom)
+	require.NoError(t, err)
+	x, y, ok := p.LatLonToPixel(25.76, -80.19, zoom)
+	require.True(t, ok)
+	assert.True(t, image.Pt(int(x), int(y)).In(crop), "Crop %v should contain Miami", crop)
+	x, y, ok = p.LatLonToPixel(40.71, -74.01, zoom)
+	require.True(t, ok)
+	assert.False(t, image.Pt(int(x), int(y)).In(crop), "Crop %v should not contain New York", crop)
+
+	_, err = p.BoundingBoxCrop(&BoundingBox{MinLon: 100, MinLat: 0, MaxLon: 110, MaxLat: 10}, zoom)
+	assert.Error(t, err, "Bounding box on the far side of the Earth should fail")
+	_, err = p.BoundingBoxCrop(&BoundingBox{MinLon: -88, MinLat: 31, MaxLon: -79, MaxLat: 24}, zoom)
+	assert.Error(t, err, "Inverted latitudes should fail")
+}
+
+func TestCenterCrop(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["full-disk"]
+	p, err := sector.Projection()
+	require.NoError(t, err)
+
+	crop, err := p.CenterCrop(LatLon{Lat: 0, Lon: -75}, image.Pt(200, 100), &Zoom{Level: 1})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(578, 628, 778, 728), crop)
+
+	_, err = p.CenterCrop(LatLon{Lat: 0, Lon: -75}, image.Pt(2000, 100), &Zoom{Level: 1})
+	assert.Error(t, err, "Crop area larger than the sector image should fail")
+	_, err = p.CenterCrop(LatLon{Lat: 0, Lon: 105}, image.Pt(200, 100), &Zoom{Level: 1})
+	assert.Error(t, err, "Center on the far side of the Earth should fail")
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// earthInverseFlattening is the GRS80 inverse flattening of the Earth.
+const earthInverseFlattening = 298.257222101
+
+// WorldFile is the affine transformation from image pixels to projected coordinates stored in an ESRI world file.
+// X and Y are the projected coordinates of the center of the upper-left-hand pixel.
+type WorldFile struct {
+	PixelWidth  float64
+	RotationY   float64
+	RotationX   float64
+	PixelHeight float64
+	X           float64
+	Y           float64
+}
+
+// String returns the world file contents.
+func (w *WorldFile) String() string {
+	var s string
+	for _, v := range []float64{w.PixelWidth, w.RotationY, w.RotationX, w.PixelHeight, w.X, w.Y} {
+		s += strconv.FormatFloat(v, 'f', -1, 64) + "\n"
+	}
+	return s
+}
+
+// Scale returns the world file with the pixel size multiplied by the factors along each axis, such as after the
+// image has been resized. An image resized to half of its width has an X factor of 2.
+func (w *WorldFile) Scale(x, y float64) *WorldFile {
+	// X and Y are moved from the center of the old upper-left-hand pixel to the center of the new one
+	return &WorldFile{
+		PixelWidth:  w.PixelWidth * x,
+		RotationY:   w.RotationY * x,
+		RotationX:   w.RotationX * y,
+		PixelHeight: w.PixelHeight * y,
+		X:           w.X + (w.PixelWidth*(x-1)+w.RotationX*(y-1))/2,
+		Y:           w.Y + (w.RotationY*(x-1)+w.PixelHeight*(y-1))/2,
+	}
+}
+
+// WorldFile returns the world file for an image of the sector at the zoom level in the geostationary projection
+// with coordinates in meters. The crop is the area of the sector image that the image was cropped to, or nil if the
+// image wasn't cropped.
+func (p *Projection) WorldFile(zoom *Zoom, crop *image.Rectangle) *WorldFile {
+	h := p.Height() * 1000
+	dx, dy := p.PixelSize(zoom)
+	cx, cy := p.origin(zoom)
+	var minX, minY float64
+	if crop != nil {
+		minX, minY = float64(crop.Min.X), float64(crop.Min.Y)
+	}
+	return &WorldFile{
+		PixelWidth:  dx * h,
+		PixelHeight: -dy * h,
+		X:           (minX + 0.5 - cx) * dx * h,
+		Y:           (cy - minY - 0.5) * dy * h,
+	}
+}
+
+// WKT returns the well-known text description of the geostationary projection used by WorldFile coordinates. The
+// sweep axis is set from the satellite.
+func (p *Projection) WKT() string {
+	lon0 := strconv.FormatFloat(p.query.Lon0, 'f', -1, 64)
+	sweep := "x"
+	if p.query.SweepY {
+		sweep = "y"
+	}
+	h := strconv.FormatFloat(math.Round(p.Height()*1e6)/1e3, 'f', -1, 64)
+	return `PROJCS["Geostationary Satellite (Lon0 ` + lon0 + `)",` +
+		`GEOGCS["GRS 1980(IUGG, 1980)",DATUM["unknown",SPHEROID["GRS80",6378137,` +
+		strconv.FormatFloat(earthInverseFlattening, 'f', -1, 64) + `]],` +
+		`PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]],` +
+		`PROJECTION["Geostationary_Satellite"],` +
+		`PARAMETER["central_meridian",` + lon0 + `],` +
+		`PARAMETER["satellite_height",` + h + `],` +
+		`PARAMETER["false_easting",0],PARAMETER["false_northing",0],UNIT["Meter",1],` +
+		`EXTENSION["PROJ4","+proj=geos +lon_0=` + lon0 + ` +h=` + h + ` +x_0=0 +y_0=0 +ellps=GRS80 +units=m ` +
+		`+no_defs +sweep=` + sweep + `"]]`
+}
+
+// SaveGeoReferencedPNG encodes the image into a .png file along with a .pgw world file and a .prj projection file
+// so that it can be loaded into GIS software. The .png extension will be added automatically. If a file with the
+// same name exists an incrementing number will be appended to the end of the file name.
+func SaveGeoReferencedPNG(output string, img image.Image, worldFile *WorldFile, wkt string) (string, error) {
+	output, err := checkFileDuplicate(output, ".png")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(output+".png", os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to open PNG file: %w", err)
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("unable to encode PNG: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to close PNG file: %w", err)
+	}
+	err = ioutil.WriteFile(output+".pgw", []byte(worldFile.String()), 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to write world file: %w", err)
+	}
+	err = ioutil.WriteFile(output+".prj", []byte(wkt), 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to write projection file: %w", err)
+	}
+	log.Debug().Msgf("Saved geo-referenced PNG to '%s'", output+".png")
+	return output + ".png", nil
+}
+
+// saveGeoReferencedFrames saves each image as a separate geo-referenced PNG named with its capture time.
+func saveGeoReferencedFrames(opts *LoopOptions, output string, images []image.Image, times []time.Time) error {
+	wkt := opts.projection.WKT()
+	for i, img := range images {
+		crop, err := frameCrop(opts, times[i])
+		if err != nil {
+			return err
+		}
+		if crop != nil {
+			// Crop areas are clipped to the sector image
+			clipped := crop.Intersect(image.Rect(0, 0, opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom)))
+			crop = &clipped
+		}
+		worldFile := opts.projection.WorldFile(opts.zoom, crop)
+		if opts.Resize != nil {
+			size := image.Pt(opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom))
+			if crop != nil {
+				size = crop.Size()
+			}
+			resized := opts.Resize.size(size)
+			worldFile = worldFile.Scale(float64(size.X)/float64(resized.X), float64(size.Y)/float64(resized.Y))
+		}
+		_, err = SaveGeoReferencedPNG(output+"_"+times[i].Format("20060102150405"), img, worldFile, wkt)
+		if err != nil {
+			return fmt.Errorf("unable to save frame %d: %w", i, err)
+		}
+	}
+	return nil
+}
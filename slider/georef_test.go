@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestWorldFile(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["full-disk"]
+	p, err := sector.Projection()
+	require.NoError(t, err)
+	zoom := &Zoom{Level: 1}
+
+	w := p.WorldFile(zoom, nil)
+	assert.InDelta(t, 8016.4, w.PixelWidth, 0.1)
+	assert.InDelta(t, -8015.2, w.PixelHeight, 0.1)
+	assert.Zero(t, w.RotationX)
+	assert.Zero(t, w.RotationY)
+	// The sub-satellite point is at the corner of the four center pixels
+	assert.InDelta(t, 0, w.X+(678-0.5)*w.PixelWidth, 1e-6)
+	assert.InDelta(t, 0, w.Y+(678-0.5)*w.PixelHeight, 1e-6)
+
+	cropped := p.WorldFile(zoom, &image.Rectangle{Min: image.Pt(100, 50), Max: image.Pt(200, 150)})
+	assert.InDelta(t, w.X+100*w.PixelWidth, cropped.X, 1e-6)
+	assert.InDelta(t, w.Y+50*w.PixelHeight, cropped.Y, 1e-6)
+	assert.Len(t, strings.Split(strings.TrimSpace(cropped.String()), "\n"), 6)
+
+	// Halving the image size doubles the pixel size and moves the center of the first pixel
+	scaled := w.Scale(2, 2)
+	assert.InDelta(t, 2*w.PixelWidth, scaled.PixelWidth, 1e-6)
+	assert.InDelta(t, w.X+w.PixelWidth/2, scaled.X, 1e-6)
+	assert.InDelta(t, w.Y+w.PixelHeight/2, scaled.Y, 1e-6)
+
+	assert.Contains(t, p.WKT(), "+proj=geos +lon_0=-75 +h=35793563")
+	assert.Contains(t, p.WKT(), "+sweep=x")
+
+	himawari, err := testInventory(t).Satellites["himawari"].Sectors["full-disk"].Projection()
+	require.NoError(t, err)
+	assert.Contains(t, himawari.WKT(), "+sweep=y", "Himawari should use the CGMS sweep axis")
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/color"
+	"image/gif"
+)
+
+// optimizeGIF replaces every frame after the first with only the area that changed from the frame before it. Pixels
+// inside of that area that didn't change are made transparent so that the previous frame shows through, which
+// compresses much better. Frames that don't change at all are removed and their delay is added to the frame before
+// them. Pixels that become transparent, such as the corners of rotated frames, are cleared by disposing of the frame
+// before them to the background. Animations with frames of different sizes are not optimized.
+func optimizeGIF(g *gif.GIF) {
+	if len(g.Image) < 2 {
+		return
+	}
+	bounds := g.Image[0].Bounds()
+	for _, frame := range g.Image {
+		if frame.Bounds() != bounds {
+			log.Debug().Msg("Not optimizing GIF with frames of different sizes")
+			return
+		}
+	}
+
+	// canvas is the image that is displayed after each frame is drawn
+	canvas := make([]color.RGBA, bounds.Dx()*bounds.Dy())
+	drawFrame(canvas, g.Image[0], g.Image[0].Bounds(), nil)
+	frames := []*image.Paletted{g.Image[0]}
+	delays := []int{g.Delay[0]}
+	disposal := []byte{gif.DisposalNone}
+	// sources is the index of the original frame that each optimized frame was made from
+	sources := []int{0}
+	for i := 1; i < len(g.Image); i++ {
+		frame := g.Image[i]
+		palette := rgbaPalette(frame.Palette)
+		changed := changedArea(canvas, frame, palette)
+		if changed.Empty() {
+			delays[len(delays)-1] += g.Delay[i]
+			continue
+		}
+		if cleared := clearedArea(canvas, frame, palette); !cleared.Empty() {
+			// Transparent pixels can't be drawn over the canvas, so the previous frame is grown to cover them and
+			// disposed of to the transparent background before this frame is drawn
+			last := len(frames) - 1
+			area := frames[last].Bounds().Union(cleared)
+			frames[last] = redrawFrame(g.Image[sources[last]], frames[last].Palette, area)
+			disposal[last] = gif.DisposalBackground
+			clearArea(canvas, bounds, area)
+			changed = changedArea(canvas, frame, palette)
+		}
+
+		// The GIF encoder uses the first fully transparent palette color as the transparent color
+		transparent := -1
+		for j, c := range frame.Palette {
+			if _, _, _, a := c.RGBA(); a == 0 {
+				transparent = j
+				break
+			}
+		}
+		deltaPalette := frame.Palette
+		if transparent < 0 && len(frame.Palette) < 256 {
+			transparent = len(frame.Palette)
+			deltaPalette = append(append(color.Palette{}, frame.Palette...), color.RGBA{})
+		}
+		delta := image.NewPaletted(changed, deltaPalette)
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			for x := changed.Min.X; x < changed.Max.X; x++ {
+				idx := frame.Pix[frame.PixOffset(x, y)]
+				if transparent >= 0 && palette[idx] == canvas[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] {
+					idx = uint8(transparent)
+				}
+				delta.Pix[delta.PixOffset(x, y)] = idx
+			}
+		}
+		drawFrame(canvas, frame, changed, palette)
+		frames = append(frames, delta)
+		delays = append(delays, g.Delay[i])
+		disposal = append(disposal, gif.DisposalNone)
+		sources = append(sources, i)
+	}
+	log.Debug().Msgf("Optimized GIF from %d to %d frames", len(g.Image), len(frames))
+
+	g.Image = frames
+	g.Delay = delays
+	g.Disposal = disposal
+}
+
+// clearedArea is the smallest area containing every transparent pixel of the frame that isn't transparent on the
+// canvas.
+func clearedArea(canvas []color.RGBA, frame *image.Paletted, palette []color.RGBA) image.Rectangle {
+	bounds := frame.Bounds()
+	var area image.Rectangle
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := palette[frame.Pix[frame.PixOffset(x, y)]]
+			if c.A == 0 && canvas[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)].A != 0 {
+				area = area.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return area
+}
+
+// redrawFrame copies the area of the original frame into a new frame with the palette. The palette must start with
+// the colors of the original frame's palette.
+func redrawFrame(frame *image.Paletted, palette color.Palette, area image.Rectangle) *image.Paletted {
+	redrawn := image.NewPaletted(area, palette)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		copy(redrawn.Pix[redrawn.PixOffset(area.Min.X, y):redrawn.PixOffset(area.Max.X, y)],
+			frame.Pix[frame.PixOffset(area.Min.X, y):frame.PixOffset(area.Max.X, y)])
+	}
+	return redrawn
+}
+
+// clearArea makes the area of the canvas transparent, which is what is displayed after a frame is disposed of to the
+// background.
+func clearArea(canvas []color.RGBA, bounds, area image.Rectangle) {
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			canvas[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] = color.RGBA{}
+		}
+	}
+}
+
+// changedArea is the smallest area containing every pixel of the frame that is different from the canvas.
+func changedArea(canvas []color.RGBA, frame *image.Paletted, palette []color.RGBA) image.Rectangle {
+	bounds := frame.Bounds()
+	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X, bounds.Min.Y
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := palette[frame.Pix[frame.PixOffset(x, y)]]
+			if c != canvas[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] {
+				if x < minX {
+					minX = x
+				}
+				if x >= maxX {
+					maxX = x + 1
+				}
+				if y < minY {
+					minY = y
+				}
+				maxY = y + 1
+			}
+		}
+	}
+	if maxX <= minX {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX, maxY)
+}
+
+// drawFrame draws the area of the frame onto the canvas.
+func drawFrame(canvas []color.RGBA, frame *image.Paletted, area image.Rectangle, palette []color.RGBA) {
+	if palette == nil {
+		palette = rgbaPalette(frame.Palette)
+	}
+	bounds := frame.Bounds()
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			// Transparent pixels leave the canvas unchanged
+			if c := palette[frame.Pix[frame.PixOffset(x, y)]]; c.A != 0 {
+				canvas[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] = c
+			}
+		}
+	}
+}
+
+// rgbaPalette converts the palette to RGBA colors with 256 entries so that any index can be looked up.
+func rgbaPalette(p color.Palette) []color.RGBA {
+	palette := make([]color.RGBA, 256)
+	for i, c := range p {
+		palette[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	return palette
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"testing"
+)
+
+func TestOptimizeGIF(t *testing.T) {
+	background := image.NewUniform(color.RGBA{R: 30, G: 80, B: 140, A: 255})
+	box := image.NewUniform(color.RGBA{R: 255, G: 200, A: 255})
+	var images []image.Image
+	for _, x := range []int{10, 20, 20, 30} {
+		img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+		draw.Draw(img, img.Bounds(), background, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x, 10, x+10, 20), box, image.Point{}, draw.Src)
+		images = append(images, img)
+	}
+
+	optimized, err := AnimateGIFWithOptions(images, 10, ForwardLoop, &GIFOptions{Optimize: true})
+	require.NoError(t, err)
+	full, err := AnimateGIFWithOptions(images, 10, ForwardLoop, &GIFOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, optimized.Image, 3, "Unchanged frames should be removed")
+	assert.Equal(t, []int{10, 20, 10}, optimized.Delay, "Delays of removed frames should be kept")
+	assert.Equal(t, image.Rect(0, 0, 100, 60), optimized.Image[0].Bounds())
+	assert.Equal(t, image.Rect(10, 10, 30, 20), optimized.Image[1].Bounds(), "Only the changed area should be kept")
+	assert.Equal(t, []byte{gif.DisposalNone, gif.DisposalNone, gif.DisposalNone}, optimized.Disposal)
+
+	// Decoding both animations should result in the same images
+	var fullBuf, optimizedBuf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&fullBuf, full))
+	require.NoError(t, gif.EncodeAll(&optimizedBuf, optimized))
+	assert.Less(t, optimizedBuf.Len(), fullBuf.Len())
+	decoded, err := gif.DecodeAll(&optimizedBuf)
+	require.NoError(t, err)
+	canvas := image.NewRGBA(image.Rect(0, 0, 100, 60))
+	for i, frame := range decoded.Image {
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		expected := []int{0, 2, 3}[i]
+		for y := 0; y < 60; y++ {
+			for x := 0; x < 100; x++ {
+				require.Equal(t, color.RGBAModel.Convert(full.Image[expected].At(x, y)), canvas.At(x, y),
+					"Frame %d differs at %d,%d", i, x, y)
+			}
+		}
+	}
+}
+
+func TestOptimizeGIFTransparency(t *testing.T) {
+	box := image.NewUniform(color.RGBA{R: 255, G: 200, A: 255})
+	background := image.NewUniform(color.RGBA{B: 140, A: 255})
+	var images []image.Image
+	for i, x := range []int{10, 20, 30} {
+		img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+		// The transparent corner grows in every frame, like the corners of a rotated frame
+		draw.Draw(img, image.Rect(20*i, 0, 100, 60), background, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x+40, 10, x+50, 20), box, image.Point{}, draw.Src)
+		images = append(images, img)
+	}
+
+	optimized, err := AnimateGIFWithOptions(images, 10, ForwardLoop, &GIFOptions{Optimize: true})
+	require.NoError(t, err)
+	full, err := AnimateGIFWithOptions(images, 10, ForwardLoop, &GIFOptions{})
+	require.NoError(t, err)
+	require.Len(t, optimized.Image, 3)
+	assert.Equal(t, []byte{gif.DisposalBackground, gif.DisposalBackground, gif.DisposalNone}, optimized.Disposal,
+		"Frames before pixels become transparent should be disposed of to the background")
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, optimized))
+	decoded, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+	canvas := image.NewRGBA(image.Rect(0, 0, 100, 60))
+	for i, frame := range decoded.Image {
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		for y := 0; y < 60; y++ {
+			for x := 0; x < 100; x++ {
+				require.Equal(t, color.RGBAModel.Convert(full.Image[i].At(x, y)), canvas.At(x, y),
+					"Frame %d differs at %d,%d", i, x, y)
+			}
+		}
+		if decoded.Disposal[i] == gif.DisposalBackground {
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"html/template"
+	"image"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// HTMLOptions are the options used to save the frames of a loop in an HTML viewer.
+type HTMLOptions struct {
+	// ExternalFrames saves the frames in a directory next to the .html file instead of embedding them in the file.
+	ExternalFrames bool
+	// JPEG encodes the frames as JPEG images. Frames are encoded as PNG images otherwise so that no colors are lost.
+	JPEG bool
+	// Quality is the JPEG quality from 1 to 100. DefaultJPEGQuality is used if Quality is zero.
+	Quality int
+}
+
+// HTMLViewer is a web page that plays the frames of a loop with controls to pause, step, scrub, and change the
+// speed of the loop. The page works offline since it doesn't load any scripts or styles from the web.
+type HTMLViewer struct {
+	// Delay is the time to display each frame in hundredths of a second at normal speed.
+	Delay  int
+	Frames []HTMLFrame
+	// Loop is the direction the frames are played in when the page is opened.
+	Loop  LoopStyle
+	Title string
+}
+
+// HTMLFrame is a single frame of an HTML viewer.
+type HTMLFrame struct {
+	Image image.Image
+	// Label is the text shown below the frame, such as its capture time.
+	Label string
+}
+
+// htmlFrame is a frame as it is passed to the viewer's script.
+type htmlFrame struct {
+	Label string `json:"label"`
+	Src   string `json:"src"`
+}
+
+// htmlViewer creates the viewer for the frames displayed at the frame times. The viewer is titled with the satellite,
+// sector, and product unless the loop has its own title. Each frame is labeled with its capture time in the time zone
+// and format of the annotation options if they are set.
+func htmlViewer(opts *LoopOptions, images []image.Image, times []time.Time, synthetic []bool) *HTMLViewer {
+	viewer := &HTMLViewer{
+		Delay:  opts.Speed,
+		Frames: make([]HTMLFrame, len(images)),
+		Loop:   opts.Loop,
+		Title:  opts.title,
+	}
+	if viewer.Title == "" {
+		viewer.Title = fmt.Sprintf("%s - %s - %s", opts.Satellite.SatelliteTitle, opts.Sector.SectorTitle,
+			opts.Product.ProductTitle)
+	}
+	for i, img := range images {
+		label := captureTimeText(opts, times[i])
+		if synthetic != nil && synthetic[i] {
+			label += " (interpolated)"
+		}
+		viewer.Frames[i] = HTMLFrame{Image: img, Label: label}
+	}
+	return viewer
+}
+
+// SaveHTML saves the viewer into a .html file. The .html extension will be added automatically. The frames are
+// embedded in the file as data URIs, or saved as numbered images in a directory named after the file with a
+// _frames suffix if ExternalFrames is set. If a file with the same name exists an incrementing number will be
+// appended to the end of the file name.
+func SaveHTML(output string, viewer *HTMLViewer, opts *HTMLOptions) (string, error) {
+	if len(viewer.Frames) == 0 {
+		return "", fmt.Errorf("no frames to save")
+	}
+	if viewer.Delay < 1 {
+		return "", fmt.Errorf("frame delay must be at least 1: %d", viewer.Delay)
+	}
+	if viewer.Loop != ForwardLoop && viewer.Loop != ReverseLoop && viewer.Loop != RockLoop {
+		return "", fmt.Errorf("unknown animation loop style: %v", viewer.Loop)
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = DefaultJPEGQuality
+	}
+	if quality < 1 || quality > 100 {
+		return "", fmt.Errorf("JPEG quality must be between 1 and 100: %d", quality)
+	}
+	output, err := checkFileDuplicate(output, ".html")
+	if err != nil {
+		return "", err
+	}
+	ext, mediaType := ".png", "image/png"
+	if opts.JPEG {
+		ext, mediaType = ".jpg", "image/jpeg"
+	}
+
+	frames := make([]htmlFrame, len(viewer.Frames))
+	if opts.ExternalFrames {
+		dir := output + "_frames"
+		err = os.Mkdir(dir, 0750)
+		if err != nil {
+			return "", fmt.Errorf("unable to create frame directory: %w", err)
+		}
+		// Frame numbers are padded so that the files sort in order
+		digits := len(strconv.Itoa(len(frames) - 1))
+		if digits < 3 {
+			digits = 3
+		}
+		for i, frame := range viewer.Frames {
+			name := fmt.Sprintf("frame_%0*d%s", digits, i, ext)
+			err = saveFrame(path.Join(dir, name), frame.Image, opts.JPEG, quality)
+			if err != nil {
+				return "", fmt.Errorf("unable to save frame %d: %w", i, err)
+			}
+			frames[i] = htmlFrame{Label: frame.Label, Src: path.Base(dir) + "/" + name}
+		}
+	} else {
+		for i, frame := range viewer.Frames {
+			var buf bytes.Buffer
+			err = encodeFrame(&buf, frame.Image, opts.JPEG, quality)
+			if err != nil {
+				return "", fmt.Errorf("unable to encode frame %d: %w", i, err)
+			}
+			frames[i] = htmlFrame{
+				Label: frame.Label,
+				Src:   "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+			}
+		}
+	}
+
+	f, err := os.OpenFile(output+".html", os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to open HTML file: %w", err)
+	}
+	err = htmlViewerTemplate.Execute(f, map[string]interface{}{
+		// Delays are given to the script in milliseconds
+		"Delay":   viewer.Delay * 10,
+		"Frames":  frames,
+		"Reverse": viewer.Loop == ReverseLoop,
+		"Rock":    viewer.Loop == RockLoop,
+		"Title":   viewer.Title,
+	})
+	if err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("unable to write HTML: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to close HTML file: %w", err)
+	}
+	log.Debug().Msgf("Saved HTML viewer to '%s'", output+".html")
+	return output + ".html", nil
+}
+
+var htmlViewerTemplate = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { background: #1e1e1e; color: #e0e0e0; font-family: sans-serif; margin: 0; text-align: center; }
+h1 { font-size: 1.2em; font-weight: normal; margin: 0.6em; }
+#frame { display: block; margin: 0 auto; max-height: 80vh; max-width: 100%; }
+#time { font-family: monospace; font-size: 1.1em; margin: 0.5em; }
+.controls { align-items: center; display: flex; flex-wrap: wrap; gap: 0.6em; justify-content: center; margin: 0.5em; }
+#slider { flex: 1; max-width: 60em; }
+button, select { background: #333; border: 1px solid #555; border-radius: 3px; color: inherit; padding: 0.3em 0.8em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<img id="frame" alt="">
+<div id="time"></div>
+<div class="controls">
+<input id="slider" type="range" min="0" value="0" aria-label="Frame">
+</div>
+<div class="controls">
+<button id="previous" title="Previous frame (Left arrow)">&#9664;&#9664;</button>
+<button id="play" title="Play or pause (Space)">Pause</button>
+<button id="next" title="Next frame (Right arrow)">&#9654;&#9654;</button>
+<label>Speed <select id="speed">
+<option value="0.25">0.25x</option>
+<option value="0.5">0.5x</option>
+<option value="1" selected>1x</option>
+<option value="2">2x</option>
+<option value="4">4x</option>
+</select></label>
+<label><input id="rock" type="checkbox"{{if .Rock}} checked{{end}}> Rock</label>
+</div>
+<script>
+(function () {
+	var frames = {{.Frames}};
+	var delay = {{.Delay}};
+	var forward = {{if .Reverse}}-1{{else}}1{{end}};
+	var direction = forward;
+	var index = forward > 0 ? 0 : frames.length - 1;
+	var timer = null;
+	var frame = document.getElementById("frame");
+	var time = document.getElementById("time");
+	var slider = document.getElementById("slider");
+	var play = document.getElementById("play");
+	var speed = document.getElementById("speed");
+	var rock = document.getElementById("rock");
+
+	// Images are loaded up front so that frames don't flicker the first time through the loop
+	for (var i = 0; i < frames.length; i++) {
+		new Image().src = frames[i].src;
+	}
+	slider.max = frames.length - 1;
+
+	function show(i) {
+		index = (i + frames.length) % frames.length;
+		frame.src = frames[index].src;
+		time.textContent = frames[index].label;
+		slider.value = index;
+	}
+
+	function advance() {
+		var i = index + direction;
+		if (i < 0 || i >= frames.length) {
+			if (rock.checked && frames.length > 1) {
+				direction = -direction;
+				i = index + direction;
+			} else {
+				i = direction > 0 ? 0 : frames.length - 1;
+			}
+		}
+		show(i);
+	}
+
+	function schedule() {
+		timer = setTimeout(function () {
+			advance();
+			schedule();
+		}, delay / parseFloat(speed.value));
+	}
+
+	function start() {
+		if (timer === null) {
+			play.textContent = "Pause";
+			schedule();
+		}
+	}
+
+	function stop() {
+		clearTimeout(timer);
+		timer = null;
+		play.textContent = "Play";
+	}
+
+	function step(offset) {
+		stop();
+		show(index + offset);
+	}
+
+	play.onclick = function () {
+		if (timer === null) {
+			start();
+		} else {
+			stop();
+		}
+	};
+	document.getElementById("previous").onclick = function () {
+		step(-1);
+	};
+	document.getElementById("next").onclick = function () {
+		step(1);
+	};
+	slider.oninput = function () {
+		stop();
+		show(parseInt(slider.value, 10));
+	};
+	rock.onchange = function () {
+		if (!rock.checked) {
+			direction = forward;
+		}
+	};
+	document.onkeydown = function (e) {
+		if (e.target.tagName === "SELECT" || e.target.tagName === "INPUT") {
+			return;
+		}
+		if (e.key === " ") {
+			play.onclick();
+		} else if (e.key === "ArrowLeft") {
+			step(-1);
+		} else if (e.key === "ArrowRight") {
+			step(1);
+		} else {
+			return;
+		}
+		e.preventDefault();
+	};
+
+	show(index);
+	start();
+})();
+</script>
+</body>
+</html>
+`))
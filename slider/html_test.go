@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTMLViewer(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	opts := &LoopOptions{
+		Loop:       RockLoop,
+		Product:    &Product{ProductTitle: "GeoColor"},
+		Satellite:  &Satellite{SatelliteTitle: "GOES-16"},
+		Sector:     &Sector{SectorTitle: "CONUS"},
+		Speed:      15,
+		Annotation: &AnnotationOptions{TimeFormat: "15:04"},
+	}
+	images := []image.Image{imaging.New(4, 4, color.Black), imaging.New(4, 4, color.White)}
+	viewer := htmlViewer(opts, images, []time.Time{start, start.Add(5 * time.Minute)}, []bool{false, true})
+	assert.Equal(t, "GOES-16 - CONUS - GeoColor", viewer.Title)
+	assert.Equal(t, 15, viewer.Delay)
+	assert.Equal(t, RockLoop, viewer.Loop)
+	require.Len(t, viewer.Frames, 2)
+	assert.Equal(t, "00:00", viewer.Frames[0].Label)
+	assert.Equal(t, "00:05 (interpolated)", viewer.Frames[1].Label)
+
+	opts.title = "Hurricane Ida"
+	viewer = htmlViewer(opts, images, []time.Time{start, start.Add(5 * time.Minute)}, nil)
+	assert.Equal(t, "Hurricane Ida", viewer.Title, "The loop's own title should be used")
+}
+
+func TestSaveHTML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slider-html")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	red := color.NRGBA{R: 255, A: 255}
+	viewer := &HTMLViewer{
+		Delay: 20,
+		Frames: []HTMLFrame{
+			{Image: imaging.New(3, 2, red), Label: "2021-08-29 00:00 UTC"},
+			{Image: imaging.New(3, 2, color.White), Label: "2021-08-29 00:10 UTC"},
+		},
+		Loop:  RockLoop,
+		Title: "<GOES-16>",
+	}
+	output, err := SaveHTML(filepath.Join(dir, "loop"), viewer, &HTMLOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "loop.html"), output)
+	data, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	page := string(data)
+	assert.Contains(t, page, "<title>&lt;GOES-16&gt;</title>", "Titles should be escaped")
+	assert.Contains(t, page, "2021-08-29 00:10 UTC")
+	assert.Regexp(t, `var delay = +200 *;`, page, "Delays should be in milliseconds")
+	assert.Contains(t, page, "checked", "Rock loops should start in rock mode")
+	assert.NotContains(t, page, "http", "The viewer shouldn't load anything from the web")
+
+	// The first frame can be decoded from its data URI
+	match := regexp.MustCompile(`data:image/png;base64,([A-Za-z0-9+/=]+)`).FindStringSubmatch(page)
+	require.NotNil(t, match)
+	raw, err := base64.StdEncoding.DecodeString(match[1])
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, red, imaging.Clone(img).NRGBAAt(0, 0))
+
+	// Frames are saved next to the viewer
+	output, err = SaveHTML(filepath.Join(dir, "loop"), viewer, &HTMLOptions{ExternalFrames: true, JPEG: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, filepath.Join(dir, "loop.html"), output, "Existing viewers shouldn't be overwritten")
+	frameDir := strings.TrimSuffix(output, ".html") + "_frames"
+	files, err := ioutil.ReadDir(frameDir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "frame_000.jpg", files[0].Name())
+	data, err = ioutil.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), filepath.Base(frameDir)+"/frame_001.jpg")
+	assert.NotContains(t, string(data), "data:image")
+
+	_, err = SaveHTML(filepath.Join(dir, "empty"), &HTMLViewer{Delay: 20}, &HTMLOptions{})
+	assert.Error(t, err)
+}
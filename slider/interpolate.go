@@ -0,0 +1,395 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InterpolationMode is the way that frames are synthesized between captured images.
+type InterpolationMode int
+
+const (
+	// Crossfade blends each pair of captured images together.
+	Crossfade InterpolationMode = iota
+	// MotionInterpolation estimates the motion between each pair of captured images with optical flow and moves
+	// the pixels of both images along it before blending them together.
+	MotionInterpolation
+)
+
+// Optical flow is estimated by matching blocks of pixels between the images, starting with the smallest images of
+// an image pyramid and refining the motion at each larger level.
+const (
+	flowBlockSize    = 8
+	flowMinLevelSize = 16
+	flowSearchRadius = 2
+	// flowCoarseSearchRadius is the search radius at the smallest level, where there is no previous estimate.
+	flowCoarseSearchRadius = 4
+	// flowMotionPenalty is added to the block difference for each pixel of motion so that featureless areas
+	// aren't moved.
+	flowMotionPenalty = 0.5
+)
+
+// InterpolationOptions are the options used to synthesize frames between captured images to make loops smoother.
+type InterpolationOptions struct {
+	// Frames is the number of frames synthesized between each pair of captured images.
+	Frames int
+	// Mode is the way that frames are synthesized.
+	Mode InterpolationMode
+}
+
+// ParseInterpolationMode parses the name of an interpolation mode. The options are "crossfade" and "motion".
+func ParseInterpolationMode(s string) (InterpolationMode, error) {
+	switch strings.ToLower(s) {
+	case "crossfade":
+		return Crossfade, nil
+	case "motion":
+		return MotionInterpolation, nil
+	default:
+		return Crossfade, fmt.Errorf("unknown interpolation mode '%s': options are 'crossfade' and 'motion'", s)
+	}
+}
+
+// interpolateImages synthesizes frames between each pair of images. The frames, their times, and whether each
+// frame was synthesized are returned. Synthesized frames are given times evenly spaced between the capture times
+// of the pair of images.
+func interpolateImages(opts *LoopOptions, images []image.Image, times []time.Time) ([]image.Image, []time.Time,
+	[]bool, error) {
+	in := opts.Interpolation
+	if in == nil || in.Frames == 0 || len(images) < 2 {
+		return images, times, make([]bool, len(images)), nil
+	}
+	if in.Frames < 0 {
+		return nil, nil, nil, fmt.Errorf("number of interpolated frames can't be negative: %d", in.Frames)
+	}
+	for _, img := range images[1:] {
+		if img.Bounds().Size() != images[0].Bounds().Size() {
+			return nil, nil, nil, fmt.Errorf("images must be the same size to interpolate: %v and %v",
+				images[0].Bounds().Size(), img.Bounds().Size())
+		}
+	}
+	log.Debug().Msgf("Interpolating %d frames between each of %d images", in.Frames, len(images))
+	timeIn := time.Now()
+	step := in.Frames + 1
+	n := (len(images)-1)*step + 1
+	frames := make([]image.Image, n)
+	frameTimes := make([]time.Time, n)
+	synthetic := make([]bool, n)
+	wg := sync.WaitGroup{}
+	for i := range images {
+		frames[i*step] = images[i]
+		frameTimes[i*step] = times[i]
+		if i == len(images)-1 {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			a, b := imaging.Clone(images[i]), imaging.Clone(images[i+1])
+			var grayA, grayB *grayImage
+			if in.Mode == MotionInterpolation {
+				grayA, grayB = newGrayImage(a), newGrayImage(b)
+			}
+			for k := 1; k < step; k++ {
+				t := float64(k) / float64(step)
+				if in.Mode == MotionInterpolation {
+					frames[i*step+k] = warpBlend(a, b, grayA, grayB, opticalFlow(grayA, grayB, t), t)
+				} else {
+					frames[i*step+k] = crossfade(a, b, t)
+				}
+				frameTimes[i*step+k] = times[i].Add(time.Duration(t * float64(times[i+1].Sub(times[i]))))
+				synthetic[i*step+k] = true
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	timeOut := time.Now()
+	log.Debug().Msgf("Interpolation took %.3fs", timeOut.Sub(timeIn).Seconds())
+	return frames, frameTimes, synthetic, nil
+}
+
+// crossfade blends the images together with t as the fraction of b.
+func crossfade(a, b *image.NRGBA, t float64) *image.NRGBA {
+	frame := image.NewNRGBA(a.Bounds())
+	for i := 0; i < len(frame.Pix); i += 4 {
+		blendPixel(frame.Pix[i:i+4], a.Pix[i:i+4], b.Pix[i:i+4], t)
+	}
+	return frame
+}
+
+// blendPixel blends the NRGBA pixels p and q into dst with t as the fraction of q. Colors are blended with
+// premultiplied alpha so that transparent pixels don't darken the result.
+func blendPixel(dst, p, q []uint8, t float64) {
+	pa, qa := float64(p[3])*(1-t), float64(q[3])*t
+	alpha := pa + qa
+	if alpha == 0 {
+		dst[0], dst[1], dst[2], dst[3] = 0, 0, 0, 0
+		return
+	}
+	for c := 0; c < 3; c++ {
+		dst[c] = uint8((float64(p[c])*pa+float64(q[c])*qa)/alpha + 0.5)
+	}
+	dst[3] = uint8(alpha + 0.5)
+}
+
+// flowField is the estimated motion of each block of pixels of a synthesized frame between two images in pixels. A
+// pixel at x in the frame at t moves from x - t*motion in the first image to x + (1-t)*motion in the second image.
+type flowField struct {
+	// cols and rows are the number of blocks along each axis.
+	cols, rows int
+	// u and v are the horizontal and vertical motion of each block.
+	u, v []float64
+}
+
+// at returns the motion at the pixel by bilinearly interpolating the motion of the surrounding block centers.
+func (f *flowField) at(x, y float64) (float64, float64) {
+	bx := math.Max(0, math.Min(float64(f.cols-1), x/flowBlockSize-0.5))
+	by := math.Max(0, math.Min(float64(f.rows-1), y/flowBlockSize-0.5))
+	x0, y0 := int(bx), int(by)
+	x1, y1 := x0+1, y0+1
+	if x1 >= f.cols {
+		x1 = x0
+	}
+	if y1 >= f.rows {
+		y1 = y0
+	}
+	fx, fy := bx-float64(x0), by-float64(y0)
+	lerp := func(field []float64) float64 {
+		top := field[y0*f.cols+x0]*(1-fx) + field[y0*f.cols+x1]*fx
+		bottom := field[y1*f.cols+x0]*(1-fx) + field[y1*f.cols+x1]*fx
+		return top*(1-fy) + bottom*fy
+	}
+	return lerp(f.u), lerp(f.v)
+}
+
+// grayImage is a single channel image with float values.
+type grayImage struct {
+	w, h int
+	pix  []float64
+}
+
+func newGrayImage(img *image.NRGBA) *grayImage {
+	size := img.Bounds().Size()
+	g := &grayImage{w: size.X, h: size.Y, pix: make([]float64, size.X*size.Y)}
+	for i := range g.pix {
+		p := img.Pix[i*4 : i*4+4]
+		g.pix[i] = (0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])) * float64(p[3]) / 255
+	}
+	return g
+}
+
+// sample bilinearly samples the image at the pixel coordinates clamped to the image.
+func (g *grayImage) sample(x, y float64) float64 {
+	x = math.Max(0, math.Min(float64(g.w-1), x))
+	y = math.Max(0, math.Min(float64(g.h-1), y))
+	x0, y0 := int(x), int(y)
+	fx, fy := x-float64(x0), y-float64(y0)
+	top := g.at(x0, y0)*(1-fx) + g.at(x0+1, y0)*fx
+	bottom := g.at(x0, y0+1)*(1-fx) + g.at(x0+1, y0+1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// at returns the value of the pixel with coordinates clamped to the image.
+func (g *grayImage) at(x, y int) float64 {
+	if x < 0 {
+		x = 0
+	} else if x >= g.w {
+		x = g.w - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= g.h {
+		y = g.h - 1
+	}
+	return g.pix[y*g.w+x]
+}
+
+// half returns the image downsampled to half of its size.
+func (g *grayImage) half() *grayImage {
+	h := &grayImage{w: (g.w + 1) / 2, h: (g.h + 1) / 2}
+	h.pix = make([]float64, h.w*h.h)
+	for y := 0; y < h.h; y++ {
+		for x := 0; x < h.w; x++ {
+			h.pix[y*h.w+x] = (g.at(2*x, 2*y) + g.at(2*x+1, 2*y) + g.at(2*x, 2*y+1) + g.at(2*x+1, 2*y+1)) / 4
+		}
+	}
+	return h
+}
+
+// opticalFlow estimates the motion of each block of pixels of the frame at t between a and b.
+func opticalFlow(a, b *grayImage, t float64) *flowField {
+	pyramidA := []*grayImage{a}
+	pyramidB := []*grayImage{b}
+	for {
+		top := pyramidA[len(pyramidA)-1]
+		if top.w < 2*flowMinLevelSize || top.h < 2*flowMinLevelSize {
+			break
+		}
+		pyramidA = append(pyramidA, top.half())
+		pyramidB = append(pyramidB, pyramidB[len(pyramidB)-1].half())
+	}
+	var flow *flowField
+	for level := len(pyramidA) - 1; level >= 0; level-- {
+		flow = matchBlocks(pyramidA[level], pyramidB[level], flow, t)
+	}
+	// Features smaller than a block are lost by the smaller levels, so only mismatches of the full size images are
+	// removed
+	flow.u = medianFilter(flow.u, flow.cols, flow.rows)
+	flow.v = medianFilter(flow.v, flow.cols, flow.rows)
+	return flow
+}
+
+// matchBlocks estimates the motion of each block of the frame at t between a and b. The motion estimated at the
+// previous smaller level of the image pyramid for the block and its neighbors are used as candidates, and the search
+// is made around the best candidate. The search is made around no motion if there is no previous level.
+func matchBlocks(a, b *grayImage, previous *flowField, t float64) *flowField {
+	flow := &flowField{cols: (a.w + flowBlockSize - 1) / flowBlockSize, rows: (a.h + flowBlockSize - 1) / flowBlockSize}
+	flow.u = make([]float64, flow.cols*flow.rows)
+	flow.v = make([]float64, flow.cols*flow.rows)
+	radius := flowCoarseSearchRadius
+	if previous != nil {
+		radius = flowSearchRadius
+	}
+	for row := 0; row < flow.rows; row++ {
+		for col := 0; col < flow.cols; col++ {
+			x0, y0 := col*flowBlockSize, row*flowBlockSize
+			// No motion is always a candidate so that featureless areas next to moving features aren't moved
+			best, gx, gy := blockCost(a, b, x0, y0, 0, 0, t, math.Inf(1)), 0, 0
+			if previous != nil {
+				for ny := -1; ny <= 1; ny++ {
+					for nx := -1; nx <= 1; nx++ {
+						u, v := previous.at(float64(x0+flowBlockSize/2+nx*flowBlockSize)/2,
+							float64(y0+flowBlockSize/2+ny*flowBlockSize)/2)
+						dx, dy := int(math.Round(2*u)), int(math.Round(2*v))
+						if cost := blockCost(a, b, x0, y0, dx, dy, t, best); cost < best {
+							best, gx, gy = cost, dx, dy
+						}
+					}
+				}
+			}
+			bestX, bestY := gx, gy
+			for dy := gy - radius; dy <= gy+radius; dy++ {
+				for dx := gx - radius; dx <= gx+radius; dx++ {
+					if cost := blockCost(a, b, x0, y0, dx, dy, t, best); cost < best {
+						best, bestX, bestY = cost, dx, dy
+					}
+				}
+			}
+			flow.u[row*flow.cols+col] = float64(bestX)
+			flow.v[row*flow.cols+col] = float64(bestY)
+		}
+	}
+	return flow
+}
+
+// blockCost returns the difference between the pixels of the block at x0, y0 moved by -t*motion in a and the pixels
+// moved by (1-t)*motion in b plus a penalty for the amount of motion. The sum stops once it reaches the limit.
+func blockCost(a, b *grayImage, x0, y0, dx, dy int, t, limit float64) float64 {
+	cost := flowMotionPenalty * math.Hypot(float64(dx), float64(dy)) * flowBlockSize * flowBlockSize
+	ax, ay := -t*float64(dx), -t*float64(dy)
+	bx, by := (1-t)*float64(dx), (1-t)*float64(dy)
+	for y := y0; y < y0+flowBlockSize && cost < limit; y++ {
+		for x := x0; x < x0+flowBlockSize; x++ {
+			cost += math.Abs(a.sample(float64(x)+ax, float64(y)+ay) - b.sample(float64(x)+bx, float64(y)+by))
+		}
+	}
+	return cost
+}
+
+// medianFilter replaces each value with the median of its 3x3 neighborhood to remove mismatched blocks.
+func medianFilter(values []float64, cols, rows int) []float64 {
+	filtered := make([]float64, len(values))
+	window := make([]float64, 0, 9)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			window = window[:0]
+			for y := row - 1; y <= row+1; y++ {
+				for x := col - 1; x <= col+1; x++ {
+					if x >= 0 && x < cols && y >= 0 && y < rows {
+						window = append(window, values[y*cols+x])
+					}
+				}
+			}
+			sort.Float64s(window)
+			filtered[row*cols+col] = window[len(window)/2]
+		}
+	}
+	return filtered
+}
+
+// warpBlend synthesizes the frame at t between a and b by moving the pixels of a forward along the flow, moving the
+// pixels of b backward along the flow, and blending them together with t as the fraction of b. The motion of each
+// pixel is chosen from the interpolated motion and the motion of the surrounding blocks by which best matches the
+// gray images so that the edges of moving features stay sharp.
+func warpBlend(a, b *image.NRGBA, grayA, grayB *grayImage, flow *flowField, t float64) *image.NRGBA {
+	frame := image.NewNRGBA(a.Bounds())
+	size := a.Bounds().Size()
+	var p, q [4]uint8
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			fx, fy := float64(x), float64(y)
+			u, v := flow.at(fx+0.5, fy+0.5)
+			best := math.Abs(grayA.sample(fx-t*u, fy-t*v) - grayB.sample(fx+(1-t)*u, fy+(1-t)*v))
+			col, row := x/flowBlockSize, y/flowBlockSize
+			for r := row - 1; r <= row+1 && best > 0; r++ {
+				for c := col - 1; c <= col+1; c++ {
+					if c < 0 || c >= flow.cols || r < 0 || r >= flow.rows {
+						continue
+					}
+					bu, bv := flow.u[r*flow.cols+c], flow.v[r*flow.cols+c]
+					diff := math.Abs(grayA.sample(fx-t*bu, fy-t*bv) - grayB.sample(fx+(1-t)*bu, fy+(1-t)*bv))
+					if diff < best {
+						best, u, v = diff, bu, bv
+					}
+				}
+			}
+			samplePixel(p[:], a, fx-t*u, fy-t*v)
+			samplePixel(q[:], b, fx+(1-t)*u, fy+(1-t)*v)
+			i := frame.PixOffset(x, y)
+			blendPixel(frame.Pix[i:i+4], p[:], q[:], t)
+		}
+	}
+	return frame
+}
+
+// samplePixel bilinearly samples the image at the pixel coordinates clamped to the image.
+func samplePixel(dst []uint8, img *image.NRGBA, x, y float64) {
+	size := img.Bounds().Size()
+	x = math.Max(0, math.Min(float64(size.X-1), x))
+	y = math.Max(0, math.Min(float64(size.Y-1), y))
+	x0, y0 := int(x), int(y)
+	x1, y1 := x0+1, y0+1
+	if x1 >= size.X {
+		x1 = x0
+	}
+	if y1 >= size.Y {
+		y1 = y0
+	}
+	fx, fy := x-float64(x0), y-float64(y0)
+	for c := 0; c < 4; c++ {
+		top := float64(img.Pix[y0*img.Stride+x0*4+c])*(1-fx) + float64(img.Pix[y0*img.Stride+x1*4+c])*fx
+		bottom := float64(img.Pix[y1*img.Stride+x0*4+c])*(1-fx) + float64(img.Pix[y1*img.Stride+x1*4+c])*fx
+		dst[c] = uint8(top*(1-fy) + bottom*fy + 0.5)
+	}
+}
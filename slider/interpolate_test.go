@@ -0,0 +1,90 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+func TestInterpolateCrossfade(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	images := []image.Image{
+		image.NewUniform(color.Black),
+		image.NewUniform(color.White),
+		image.NewUniform(color.Black),
+	}
+	for i, img := range images {
+		frame := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+		draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+		images[i] = frame
+	}
+	times := []time.Time{start, start.Add(20 * time.Minute), start.Add(40 * time.Minute)}
+	opts := &LoopOptions{Interpolation: &InterpolationOptions{Frames: 3, Mode: Crossfade}}
+
+	frames, frameTimes, synthetic, err := interpolateImages(opts, images, times)
+	require.NoError(t, err)
+	require.Len(t, frames, 9)
+	assert.Equal(t, []bool{false, true, true, true, false, true, true, true, false}, synthetic)
+	assert.Equal(t, images[1], frames[4], "Captured images should be kept")
+	assert.Equal(t, start.Add(15*time.Minute), frameTimes[3])
+	assert.Equal(t, color.NRGBA{R: 128, G: 128, B: 128, A: 255}, frames[2].At(5, 5))
+	assert.Equal(t, color.NRGBA{R: 64, G: 64, B: 64, A: 255}, frames[7].At(5, 5))
+
+	frames, _, synthetic, err = interpolateImages(&LoopOptions{}, images, times)
+	require.NoError(t, err)
+	assert.Len(t, frames, 3)
+	assert.Equal(t, []bool{false, false, false}, synthetic)
+}
+
+func TestInterpolateMotion(t *testing.T) {
+	// A textured box moves 16 pixels to the right
+	box := func(x int) *image.NRGBA {
+		img := image.NewNRGBA(image.Rect(0, 0, 128, 96))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{B: 80, A: 255}), image.Point{}, draw.Src)
+		for y := 32; y < 56; y++ {
+			for i := 0; i < 24; i++ {
+				img.SetNRGBA(x+i, y, color.NRGBA{R: 255, G: uint8(i * 10), B: uint8(y * 4), A: 255})
+			}
+		}
+		return img
+	}
+	a, b, expected := box(40), box(56), box(48)
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(10 * time.Minute)}
+
+	difference := func(img image.Image) float64 {
+		var sum float64
+		nrgba := img.(*image.NRGBA)
+		for i := range nrgba.Pix {
+			sum += float64(abs(int(nrgba.Pix[i]) - int(expected.Pix[i])))
+		}
+		return sum / float64(len(nrgba.Pix))
+	}
+	motion, _, _, err := interpolateImages(&LoopOptions{Interpolation: &InterpolationOptions{
+		Frames: 1, Mode: MotionInterpolation}}, []image.Image{a, b}, times)
+	require.NoError(t, err)
+	fade, _, _, err := interpolateImages(&LoopOptions{Interpolation: &InterpolationOptions{Frames: 1}},
+		[]image.Image{a, b}, times)
+	require.NoError(t, err)
+	assert.Less(t, difference(motion[1]), difference(fade[1])/4, "The box should be moved halfway")
+	assert.Equal(t, color.NRGBA{B: 80, A: 255}, motion[1].At(5, 5), "The background should not change")
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// ColorTableURI is the address of the color table legend images on SLIDER.
+//  - Color Table Name
+var ColorTableURI = "https://rammb-slider.cira.colostate.edu/images/colortables/%s.png"
+
+//go:generate go run colortables_generate.go
+
+// legendBackground is the color behind legends attached to frames.
+var legendBackground = color.NRGBA{A: 255}
+
+// LegendOptions are the options used to attach a color table legend below an animation.
+type LegendOptions struct {
+	// Offline uses the color tables stored in the cache directory or bundled with slider-cli instead of downloading
+	// them from SLIDER.
+	Offline bool
+}
+
+// legendImage returns the product's color table legend image from SLIDER sized to attach below frames of the size.
+// The image keeps its own size so that the labels drawn in it aren't stretched, and is only shrunk if it is wider than
+// the frames. The bundled color table is used if the image can't be downloaded or read from the cache. Nil is returned
+// with a warning if the product doesn't have a color table or no image is available.
+func legendImage(opts *LoopOptions, frame image.Point) image.Image {
+	name := opts.Product.ColorTableName
+	if name == "" {
+		log.Warn().Msgf("Product '%s' does not have a color table -- no legend will be attached.", opts.Product.ID())
+		return nil
+	}
+
+	uri := fmt.Sprintf(ColorTableURI, name)
+	var img image.Image
+	var err error
+	if opts.Legend.Offline {
+		img, err = cachedColorTable(opts, uri)
+	} else if opts.CacheDirectory != "" {
+		img, err = cachedImageDownload(opts, uri)
+	} else {
+		img, err = DownloadImage(uri)
+	}
+	if err != nil {
+		log.Warn().Msgf("Unable to get color table '%s' -- using the bundled color table: %v", name, err)
+	}
+	if img == nil {
+		img, err = bundledColorTable(name)
+		if err != nil {
+			log.Warn().Msgf("Unable to read bundled color table '%s' -- no legend will be attached: %v", name, err)
+			return nil
+		}
+	}
+	if img == nil {
+		log.Warn().Msgf("Color table '%s' is not cached or bundled with slider-cli -- no legend will be attached.",
+			name)
+		return nil
+	}
+	if img.Bounds().Dx() > frame.X {
+		return imaging.Resize(img, frame.X, 0, imaging.Lanczos)
+	}
+	return img
+}
+
+// bundledColorTable returns the color table image bundled with slider-cli, or nil if the color table isn't bundled.
+func bundledColorTable(name string) (image.Image, error) {
+	data, ok := bundledColorTables[name]
+	if !ok {
+		return nil, nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode color table: %w", err)
+	}
+	return img, nil
+}
+
+// cachedColorTable returns the color table image at the URI from the cache directory, or nil if it isn't cached.
+func cachedColorTable(opts *LoopOptions, uri string) (image.Image, error) {
+	if opts.CacheDirectory == "" {
+		return nil, nil
+	}
+	filePath, err := URLToFilePath(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert URL to file path: %s: %w", uri, err)
+	}
+	c := ImageCache{Dir: opts.CacheDirectory}
+	img, err := c.Get(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image cache: %w", err)
+	}
+	return img, nil
+}
+
+// attachLegend attaches the product color table legend centered below each image.
+func attachLegend(opts *LoopOptions, images []image.Image) ([]image.Image, error) {
+	if opts.Legend == nil || len(images) == 0 {
+		return images, nil
+	}
+	frame := images[0].Bounds().Size()
+	legend := legendImage(opts, frame)
+	if legend == nil {
+		return images, nil
+	}
+
+	size := image.Pt(frame.X, frame.Y+legend.Bounds().Dy())
+	offset := image.Pt((frame.X-legend.Bounds().Dx())/2, frame.Y)
+	attached := make([]image.Image, len(images))
+	for i, img := range images {
+		canvas := imaging.New(size.X, size.Y, legendBackground)
+		draw.Draw(canvas, img.Bounds().Sub(img.Bounds().Min), img, img.Bounds().Min, draw.Src)
+		draw.Draw(canvas, legend.Bounds().Sub(legend.Bounds().Min).Add(offset), legend, legend.Bounds().Min, draw.Over)
+		attached[i] = canvas
+	}
+	return attached, nil
+}
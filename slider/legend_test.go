@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAttachLegend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slider-legend")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePath, err := URLToFilePath(fmt.Sprintf(ColorTableURI, "ircimss2"))
+	require.NoError(t, err)
+	cache := &ImageCache{Dir: dir}
+	require.NoError(t, cache.Write(filePath, imaging.New(300, 20, color.White)))
+
+	frames := []image.Image{image.NewNRGBA(image.Rect(0, 0, 600, 400))}
+	opts := &LoopOptions{
+		CacheDirectory: dir,
+		Product:        &Product{ColorTableName: "ircimss2"},
+		Legend:         &LegendOptions{Offline: true},
+	}
+	attached, err := attachLegend(opts, frames)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(600, 420), attached[0].Bounds().Size(), "Legends should keep their own size")
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, color.NRGBAModel.Convert(attached[0].At(150, 410)),
+		"Legends should be centered below the frames")
+	assert.Equal(t, legendBackground, color.NRGBAModel.Convert(attached[0].At(149, 410)))
+
+	// Legends wider than the frames are shrunk without changing their aspect ratio
+	require.NoError(t, cache.Write(filePath, imaging.New(1200, 40, color.White)))
+	attached, err = attachLegend(opts, frames)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(600, 420), attached[0].Bounds().Size())
+
+	// Bundled color tables are used if the color table isn't cached
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, imaging.New(200, 30, color.White)))
+	bundledColorTables["bundled-test"] = buf.Bytes()
+	defer delete(bundledColorTables, "bundled-test")
+	opts.Product.ColorTableName = "bundled-test"
+	attached, err = attachLegend(opts, frames)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(600, 430), attached[0].Bounds().Size())
+
+	// Legends are skipped if there is no color table
+	opts.Product.ColorTableName = "not-bundled-test"
+	attached, err = attachLegend(opts, frames)
+	require.NoError(t, err)
+	assert.Equal(t, frames, attached, "Color tables that aren't cached or bundled should be skipped")
+	opts.Product.ColorTableName = ""
+	attached, err = attachLegend(opts, frames)
+	require.NoError(t, err)
+	assert.Equal(t, frames, attached, "Products without a color table should be skipped")
+}
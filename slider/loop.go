@@ -37,25 +37,70 @@ type LoopOptions struct {
 	AllowStaleImages bool
 	// Angle is the number of degrees to rotate the image.
 	Angle float64
+	// Annotation contains the options for drawing the capture time and other labels onto each frame. No labels are
+	// drawn if Annotation is nil.
+	Annotation *AnnotationOptions
 	// BeginTime is the desired capture time of the first image in the loop.
 	BeginTime time.Time
+	// Budget contains the options for reducing GIF, PNG, and WebP animations to fit within a maximum file size. The
+	// file size isn't limited if Budget is nil.
+	Budget *BudgetOptions
+	// BoundingBox is the geographic area to crop the animation to. This requires a sector with LatLonQuery
+	// parameters and can't be used with Crop or Center. This is the area of the mosaic if Mosaic is set.
+	BoundingBox *BoundingBox
 	// CacheDirectory is the directory to cache downloaded images in. Caching will only happen if a directory is
 	// supplied here.
 	CacheDirectory string
+	// Center is the geographic point to center the crop area on. CenterSize must also be set. This requires a
+	// sector with LatLonQuery parameters and can't be used with Crop or BoundingBox.
+	Center *LatLon
+	// CenterSize is the size in pixels of the crop area centered on Center or Track.
+	CenterSize image.Point
+	// Compare contains the options for tiling several loops with the same capture times into one comparison
+	// animation. Only this loop is animated if Compare is nil.
+	Compare *CompareOptions
+	// ContactSheet contains the options used to lay out the frames in a contact sheet. The default options are used
+	// if ContactSheet is nil.
+	ContactSheet *ContactSheetOptions
 	// Crop is the area to crop the animation to.
 	Crop *image.Rectangle
+	// Difference contains the options for replacing the imagery with the change in brightness between frames. The
+	// imagery is animated if Difference is nil.
+	Difference *DifferenceOptions
 	// EndTime is the desired capture time of the last image in the loop.
 	EndTime time.Time
 	// FileFormat is the output file format of the animation.
 	FileFormat FileFormat
+	// Footer contains the options for attaching an attribution footer below each frame. No footer is attached if
+	// Footer is nil.
+	Footer *FooterOptions
+	// Frames contains the options used to save each frame as a separate image. The default options are used if
+	// Frames is nil.
+	Frames *FramesOptions
+	// GIF contains the options used to encode GIF animations. The default GIF options are used if GIF is nil.
+	GIF *GIFOptions
+	// HTML contains the options used to save the frames in an HTML viewer. The default options are used if HTML is
+	// nil.
+	HTML *HTMLOptions
+	// Interpolation contains the options for synthesizing frames between captured images to make the loop smoother.
+	// Every frame of the loop shows a captured image if Interpolation is nil.
+	Interpolation *InterpolationOptions
+	// Legend contains the options for attaching the product's color table legend to the animation. No legend is
+	// attached if Legend is nil.
+	Legend *LegendOptions
 	// LoopStyle is the animation style of the output animation.
 	Loop LoopStyle
+	// Mosaic contains the options for combining the imagery of neighboring satellites into one loop reprojected onto
+	// a latitude/longitude grid covering BoundingBox. Only the loop's satellite is animated if Mosaic is nil.
+	Mosaic *MosaicOptions
 	// NumberOfImages is the number of frames in the output animation.
 	NumberOfImages int
 	// OutputDirectory is the directory to save output animations in.
 	OutputDirectory string
 	// Product is the product to request imagery for.
 	Product *Product
+	// Resize contains the options for resizing each frame. Frames are not resized if Resize is nil.
+	Resize *ResizeOptions
 	// Satellite is the satellite to request imagery from.
 	Satellite *Satellite
 	// Sector is the sector to request imagery for.
@@ -64,10 +109,29 @@ type LoopOptions struct {
 	Speed int
 	// TimeStep is the interval between image capture times in minutes.
 	TimeStep int
+	// Timing contains the options for varying the time that each frame is displayed. Every frame is displayed for
+	// Speed if Timing is nil.
+	Timing *TimingOptions
+	// Track is the path of a moving feature to center the crop area on. The crop area of CenterSize is centered on
+	// the track position at the capture time of each image. This requires a sector with LatLonQuery parameters and
+	// can't be used with Crop, BoundingBox, or Center.
+	Track *Track
+	// Video contains the options used to encode videos. The default options are used if Video is nil.
+	Video *VideoOptions
+	// Watermark contains the options for drawing an image watermark onto each frame. No watermark is drawn if
+	// Watermark is nil.
+	Watermark *WatermarkOptions
+	// WebP contains the options used to encode WebP animations. The default options are used if WebP is nil.
+	WebP *WebPOptions
 	// ZoomLevel is the zoom level to request imagery for. Increasing ZoomLevel increases the output animation
 	// resolution and therefore filesize.
-	ZoomLevel int
-	zoom      *Zoom
+	ZoomLevel  int
+	centerSize image.Point
+	crop       *image.Rectangle
+	mosaic     *mosaicGrid
+	projection *Projection
+	title      string
+	zoom       *Zoom
 }
 
 // FileFormat is an output file format type.
@@ -78,19 +142,60 @@ const (
 	GIF FileFormat = iota
 	// PNG is the .png file format.
 	PNG
+	// GeoPNG saves each frame as a separate .png file with a .pgw world file and .prj projection file in the
+	// satellite's geostationary projection. This requires a sector with LatLonQuery parameters. Frames are not
+	// rotated since that would break the geo-referencing.
+	GeoPNG
+	// WebP is the .webp file format.
+	WebP
+	// Video is a video file encoded with ffmpeg, or a Motion-JPEG .avi file if ffmpeg can't be found.
+	Video
+	// Frames saves each frame as a separate numbered .png or .jpg file in a directory along with a manifest.json file
+	// describing each frame.
+	Frames
+	// ContactSheet lays out the frames in a grid with their capture times in a single .png or .jpg file.
+	ContactSheet
+	// HTML saves the frames in a .html file with controls to pause, step, scrub, and change the speed of the loop
+	// offline.
+	HTML
 )
 
 // CreateLoop creates a new loop with the options specified in the provided LoopOptions.
 func CreateLoop(opts *LoopOptions) error {
+	loop, err := renderLoop(opts)
+	if err != nil {
+		return err
+	}
+
+	// Animate
+	firstTimestamp := loop.captured[0].Format("20060102150405")
+	lastTimestamp := loop.captured[len(loop.captured)-1].Format("20060102150405")
+	outPath := path.Join(opts.OutputDirectory, makeFileName(opts, firstTimestamp, lastTimestamp))
+	return saveLoop(opts, outPath, loop.images, loop.times, loop.synthetic)
+}
+
+// renderedLoop contains the finished frames of a loop before they are saved.
+type renderedLoop struct {
+	images []image.Image
+	// times is the capture time shown in each frame.
+	times []time.Time
+	// synthetic is whether each frame was interpolated between captured images.
+	synthetic []bool
+	// captured is the capture times of the images selected for the loop.
+	captured []time.Time
+}
+
+// renderLoop downloads the images selected for the loop and creates the finished frames.
+func renderLoop(opts *LoopOptions) (*renderedLoop, error) {
 	estimateCount := opts.NumberOfImages * opts.TimeStep * 5
 	latestTimesUnfiltered, err := LatestTimes(opts.Satellite, opts.Sector, opts.Product, estimateCount)
 	if err != nil {
-		return fmt.Errorf("unable to get latest times: %w", err)
+		return nil, fmt.Errorf("unable to get latest times: %w", err)
 	}
 
 	selectedTimes, err := SelectTimestamps(latestTimesUnfiltered, opts)
 	if err != nil {
-		return fmt.Errorf("unable to select timestamps: %w", err)
+		return nil, fmt.Errorf("unable to select timestamps: %w", err)
 	}
 
 	if opts.NumberOfImages > len(selectedTimes) {
@@ -98,42 +203,306 @@ func CreateLoop(opts *LoopOptions) error {
 			"Continuing with the maximum amount.", len(selectedTimes), opts.NumberOfImages)
 	}
 
-	if (opts.Sector.MaxZoomLevel - opts.Product.ZoomLevelAdjust) < opts.ZoomLevel {
-		return fmt.Errorf("ZoomLevel %d is greater than sector or product max of %d",
-			opts.ZoomLevel, opts.Sector.MaxZoomLevel-opts.Product.ZoomLevelAdjust)
+	err = validateLoopOptions(opts, selectedTimes)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mosaic != nil {
+		err = prepareMosaic(opts)
+	} else {
+		err = prepareArea(opts)
+	}
+	if err != nil {
+		return nil, err
 	}
-
-	opts.zoom = opts.Satellite.ZoomLevels()[opts.ZoomLevel]
 
 	// Get/Download Images
-	images, err := getImages(opts, selectedTimes)
+	images, frameTimes, err := loopImages(opts, selectedTimes)
 	if err != nil {
-		return fmt.Errorf("unable to get images: %w", err)
+		return nil, err
+	}
+	synthetic := make([]bool, len(images))
+	if opts.FileFormat == ContactSheet {
+		sheet, err := contactSheet(opts, images, frameTimes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to lay out contact sheet: %w", err)
+		}
+		images = []image.Image{sheet}
+	} else if opts.Compare == nil || !opts.Compare.overlay() {
+		images, frameTimes, synthetic, err = interpolateImages(opts, images, frameTimes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to interpolate images: %w", err)
+		}
 	}
 
-	// Animate
-	firstTimestamp := selectedTimes[0].Format("20060102150405")
-	lastTimestamp := selectedTimes[len(selectedTimes)-1].Format("20060102150405")
-	outPath := path.Join(opts.OutputDirectory, makeFileName(opts, firstTimestamp, lastTimestamp))
+	// Annotate
+	images, err = decorateImages(opts, images)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decorate images: %w", err)
+	}
+	if opts.FileFormat != ContactSheet {
+		images, err = annotateImages(opts, images, frameTimes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to annotate images: %w", err)
+		}
+	}
+	if opts.Difference == nil {
+		images, err = attachLegend(opts, images)
+		if err != nil {
+			return nil, fmt.Errorf("unable to attach legend: %w", err)
+		}
+	}
+	return &renderedLoop{images: images, times: frameTimes, synthetic: synthetic, captured: selectedTimes}, nil
+}
+
+// validateLoopOptions checks that the options can be used together and warns about options that will not be used.
+// The times are the capture times of the images selected for the loop.
+func validateLoopOptions(opts *LoopOptions, selectedTimes []time.Time) error {
+	if opts.Mosaic != nil && opts.Compare != nil {
+		return fmt.Errorf("mosaics can't be used in comparison loops")
+	}
+	if opts.FileFormat == GeoPNG {
+		err := checkGeoReferencing(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Budget != nil && opts.FileFormat != GIF && opts.FileFormat != PNG && opts.FileFormat != WebP &&
+		opts.FileFormat != GeoPNG {
+		log.Warn().Msg("The file size budget only applies to GIF, PNG, and WebP animations and will not be used.")
+	}
+	if opts.Timing != nil && opts.FileFormat != GIF && opts.FileFormat != PNG && opts.FileFormat != WebP {
+		log.Warn().Msg("Frame timing only applies to GIF, PNG, and WebP animations and will not be used.")
+	}
+	if opts.Difference != nil && opts.Compare != nil {
+		return fmt.Errorf("frame differences can't be used in comparison loops")
+	}
+	if opts.Difference != nil && opts.Legend != nil {
+		log.Warn().Msg("The product legend doesn't apply to difference loops and will not be attached.")
+	}
+	if opts.Compare != nil && opts.Compare.overlay() {
+		if opts.Interpolation != nil {
+			log.Warn().Msg("Interpolation doesn't apply to swipe and flicker comparisons and will not be used.")
+		}
+		if opts.Timing != nil && opts.Timing.Proportional {
+			log.Warn().Msg("Proportional frame delays don't apply to swipe and flicker comparisons and will not be " +
+				"used.")
+		}
+	}
+	if opts.FileFormat == ContactSheet {
+		if opts.Interpolation != nil {
+			log.Warn().Msg("Interpolation doesn't apply to contact sheets and will not be used.")
+		}
+		if opts.Annotation != nil {
+			log.Warn().Msg("Labels aren't drawn on contact sheets since each frame is captioned with its capture " +
+				"time.")
+		}
+	}
+	if opts.Track != nil {
+		return checkTrack(opts, selectedTimes)
+	}
+	return nil
+}
+
+// checkGeoReferencing checks that the options can be geo-referenced and resolves the projection of the sector.
+// Options that would break the geo-referencing are removed with a warning.
+func checkGeoReferencing(opts *LoopOptions) error {
+	if opts.Compare != nil {
+		return fmt.Errorf("comparison loops can't be geo-referenced")
+	}
+	if opts.Mosaic != nil {
+		return fmt.Errorf("mosaics can't be geo-referenced")
+	}
+	if opts.Interpolation != nil && opts.Interpolation.Frames > 0 {
+		return fmt.Errorf("interpolated frames can't be geo-referenced")
+	}
+	var err error
+	opts.projection, err = opts.Sector.Projection()
+	if err != nil {
+		return fmt.Errorf("unable to geo-reference images: %w", err)
+	}
+	if opts.Angle != 0 {
+		log.Warn().Msg("Images will not be rotated since rotation would break the geo-referencing.")
+	}
+	if opts.Budget != nil {
+		log.Warn().Msg("The file size budget only applies to animations and will not be used.")
+	}
+	if opts.Annotation != nil || opts.Footer != nil || opts.Legend != nil || opts.Watermark != nil {
+		// Decorations would cover the imagery or add pixels that the world file maps to ground positions
+		log.Warn().Msg("Labels, footers, legends, and watermarks will not be drawn since they would break the " +
+			"geo-referencing.")
+		opts.Annotation, opts.Footer, opts.Legend, opts.Watermark = nil, nil, nil, nil
+	}
+	return nil
+}
+
+// checkTrack checks that the options can be used with a track and warns about capture times outside of the track.
+func checkTrack(opts *LoopOptions, selectedTimes []time.Time) error {
+	overlayCompare := opts.Compare != nil && opts.Compare.overlay()
+	if opts.Interpolation != nil && opts.Interpolation.Frames > 0 && opts.FileFormat != ContactSheet &&
+		!overlayCompare {
+		// Frames blended from two different crop areas don't match any position on the track
+		return fmt.Errorf("interpolated frames can't be used with a track")
+	}
+	for _, timestamp := range selectedTimes {
+		if !opts.Track.Covers(timestamp) {
+			log.Warn().Msgf("Image capture time %v is outside of the track -- using the nearest track "+
+				"position.", timestamp)
+		}
+	}
+	return nil
+}
+
+// loopImages gets the images of the loop at the selected times and differences them if frame differences are set.
+// The capture time shown in each image is returned with the images.
+func loopImages(opts *LoopOptions, selectedTimes []time.Time) ([]image.Image, []time.Time, error) {
+	var images []image.Image
+	var err error
+	frameTimes := selectedTimes
+	if opts.Compare != nil {
+		images, frameTimes, err = compareImages(opts, selectedTimes)
+	} else if opts.Mosaic != nil {
+		images, err = mosaicImages(opts, selectedTimes)
+	} else {
+		images, err = getImages(opts, selectedTimes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get images: %w", err)
+	}
+	images, frameTimes, err = differenceImages(opts, images, frameTimes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to difference images: %w", err)
+	}
+	return images, frameTimes, nil
+}
+
+// saveAnimation animates the images into a GIF, PNG, or WebP animation and saves it. Animations that were already
+// encoded to fit within the file size budget are saved without encoding them again.
+func saveAnimation(opts *LoopOptions, outPath string, images []image.Image, frameTimes []time.Time) error {
 	switch opts.FileFormat {
 	case GIF:
-		animation, err := AnimateGIF(images, opts.Speed, opts.Loop)
+		animation, encoded, err := animateGIF(opts, images, frameTimes)
 		if err != nil {
 			return fmt.Errorf("unable to create animation: %w", err)
 		}
-		_, err = SaveGIF(outPath, animation)
+		if encoded == nil {
+			_, err = SaveGIF(outPath, animation)
+		} else {
+			_, err = saveEncoded(outPath, ".gif", encoded)
+		}
 		if err != nil {
 			return fmt.Errorf("unable to save animation: %w", err)
 		}
 	case PNG:
-		animation, err := AnimatePNG(images, opts.Speed, opts.Loop)
+		animation, encoded, err := animatePNG(opts, images, frameTimes)
+		if err != nil {
+			return fmt.Errorf("unable to create animation: %w", err)
+		}
+		if encoded == nil {
+			_, err = SavePNG(outPath, animation)
+		} else {
+			_, err = saveEncoded(outPath, ".png", encoded)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to save animation: %w", err)
+		}
+	case WebP:
+		animation, encoded, err := animateWebP(opts, images, frameTimes)
 		if err != nil {
 			return fmt.Errorf("unable to create animation: %w", err)
 		}
-		_, err = SavePNG(outPath, animation)
+		if encoded == nil {
+			_, err = SaveWebP(outPath, animation)
+		} else {
+			_, err = saveEncoded(outPath, ".webp", encoded)
+		}
 		if err != nil {
 			return fmt.Errorf("unable to save animation: %w", err)
 		}
+	default:
+		return fmt.Errorf("file format %v is not an animation", opts.FileFormat)
+	}
+	return nil
+}
+
+// prepareArea checks the zoom level and resolves the zoom and crop area that imagery is requested for.
+func prepareArea(opts *LoopOptions) error {
+	if (opts.Sector.MaxZoomLevel - opts.Product.ZoomLevelAdjust) < opts.ZoomLevel {
+		return fmt.Errorf("ZoomLevel %d is greater than sector or product max of %d",
+			opts.ZoomLevel, opts.Sector.MaxZoomLevel-opts.Product.ZoomLevelAdjust)
+	}
+
+	opts.zoom = opts.Satellite.ZoomLevels()[opts.ZoomLevel]
+
+	err := resolveCrop(opts)
+	if err != nil {
+		return fmt.Errorf("unable to determine crop area: %w", err)
+	}
+	if opts.Resize != nil && opts.Resize.AutoZoom {
+		err = autoZoom(opts)
+		if err != nil {
+			return fmt.Errorf("unable to choose zoom level: %w", err)
+		}
+	}
+	return nil
+}
+
+// saveLoop encodes the finished frames displayed at the frame times in the output file format and saves them to
+// the output path. Synthetic marks the frames that were interpolated rather than captured.
+func saveLoop(opts *LoopOptions, outPath string, images []image.Image, frameTimes []time.Time,
+	synthetic []bool) error {
+	switch opts.FileFormat {
+	case GIF, PNG, WebP:
+		return saveAnimation(opts, outPath, images, frameTimes)
+	case Video:
+		videoOpts := opts.Video
+		if videoOpts == nil {
+			videoOpts = &VideoOptions{}
+		}
+		video, err := AnimateVideo(images, opts.Speed, opts.Loop, videoOpts)
+		if err != nil {
+			return fmt.Errorf("unable to create video: %w", err)
+		}
+		_, err = SaveVideo(outPath, video)
+		if err != nil {
+			return fmt.Errorf("unable to save video: %w", err)
+		}
+	case Frames:
+		framesOpts := opts.Frames
+		if framesOpts == nil {
+			framesOpts = &FramesOptions{}
+		}
+		manifest, err := frameManifest(opts, frameTimes, synthetic)
+		if err != nil {
+			return fmt.Errorf("unable to create frame manifest: %w", err)
+		}
+		_, err = SaveFrames(outPath, images, manifest, framesOpts)
+		if err != nil {
+			return fmt.Errorf("unable to save frames: %w", err)
+		}
+	case ContactSheet:
+		sheetOpts := opts.ContactSheet
+		if sheetOpts == nil {
+			sheetOpts = &ContactSheetOptions{}
+		}
+		_, err := SaveContactSheet(outPath, images[0], sheetOpts)
+		if err != nil {
+			return fmt.Errorf("unable to save contact sheet: %w", err)
+		}
+	case HTML:
+		htmlOpts := opts.HTML
+		if htmlOpts == nil {
+			htmlOpts = &HTMLOptions{}
+		}
+		_, err := SaveHTML(outPath, htmlViewer(opts, images, frameTimes, synthetic), htmlOpts)
+		if err != nil {
+			return fmt.Errorf("unable to save HTML viewer: %w", err)
+		}
+	case GeoPNG:
+		err := saveGeoReferencedFrames(opts, outPath, images, frameTimes)
+		if err != nil {
+			return fmt.Errorf("unable to save geo-referenced images: %w", err)
+		}
 	default:
 		return fmt.Errorf("unrecognized output file format %v", opts.FileFormat)
 	}
@@ -145,57 +514,26 @@ func getImages(opts *LoopOptions, selectedTimes []time.Time) ([]image.Image, err
 	images := make([]image.Image, len(selectedTimes))
 	lock := sync.Mutex{}
 	wg := sync.WaitGroup{}
-	errChan := make(chan error)
+	// Every goroutine can send an error without blocking after the first error is returned
+	errChan := make(chan error, len(selectedTimes))
 	for i, timestamp := range selectedTimes {
 		wg.Add(1)
 		go func(timestamp time.Time, i int) {
-			canvas := imaging.New(opts.Sector.TileSize*opts.zoom.NumTiles(), opts.Sector.TileSize*opts.zoom.NumTiles(), color.NRGBA{})
-			for x := 0; x < opts.zoom.NumTiles(); x++ {
-				for y := 0; y < opts.zoom.NumTiles(); y++ {
-					imageTileURL := ImageTileURL(&TileImageRequest{
-						Date:           timestamp.Format("2006/01/02"),
-						Satellite:      opts.Satellite.Value,
-						Sector:         opts.Sector.Value,
-						Product:        opts.Product.Value,
-						ImageTimestamp: timestamp.Format("20060102150405"),
-						ZoomLevel:      opts.ZoomLevel,
-						TileXPosition:  x,
-						TileYPosition:  y,
-					})
-					var tile image.Image
-					var err error
-					if opts.CacheDirectory != "" {
-						tile, err = cachedImageDownload(opts, imageTileURL)
-						if err != nil {
-							errChan <- fmt.Errorf("unable to get image for timestamp %v: %w", timestamp, err)
-							return
-						}
-					} else {
-						tile, err = DownloadImage(imageTileURL)
-						if err != nil {
-							errChan <- fmt.Errorf("unable to download image for timestamp %v: %w", timestamp, err)
-							return
-						}
-					}
-					canvas = imaging.Paste(canvas, tile, image.Pt(x*opts.Sector.TileSize, y*opts.Sector.TileSize))
-				}
+			defer wg.Done()
+			crop, err := frameCrop(opts, timestamp)
+			if err != nil {
+				errChan <- err
+				return
 			}
-			if opts.Sector.CropRatioX > 0 && opts.Sector.CropRatioY > 0 {
-				canvas = imaging.CropAnchor(canvas, opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom), imaging.Center)
-			}
-
-			if opts.Crop != nil {
-				canvas = imaging.Crop(canvas, *opts.Crop)
-			}
-
-			if opts.Angle != 0 {
-				canvas = imaging.Rotate(canvas, opts.Angle, image.Transparent)
+			canvas, err := getFrame(opts, timestamp, crop)
+			if err != nil {
+				errChan <- err
+				return
 			}
 
 			lock.Lock()
 			images[i] = canvas
 			lock.Unlock()
-			wg.Done()
 		}(timestamp, i)
 	}
 
@@ -216,6 +554,91 @@ func getImages(opts *LoopOptions, selectedTimes []time.Time) ([]image.Image, err
 	return images, nil
 }
 
+// getFrame downloads the tiles for a single image capture time and composites them into the final cropped,
+// rotated, and resized image. Only the tiles that intersect the crop area are downloaded.
+func getFrame(opts *LoopOptions, timestamp time.Time, crop *image.Rectangle) (image.Image, error) {
+	area := cropArea(opts.Sector, opts.zoom, crop)
+	if area.Empty() {
+		return nil, fmt.Errorf("crop area %v does not overlap the %dx%d sector image", *crop,
+			opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom))
+	}
+	tiles := tilesCovering(area, opts.Sector.TileSize)
+
+	canvas := imaging.New(opts.Sector.TileSize*tiles.Dx(), opts.Sector.TileSize*tiles.Dy(), color.NRGBA{})
+	for x := tiles.Min.X; x < tiles.Max.X; x++ {
+		for y := tiles.Min.Y; y < tiles.Max.Y; y++ {
+			imageTileURL := ImageTileURL(&TileImageRequest{
+				Date:           timestamp.Format("2006/01/02"),
+				Satellite:      opts.Satellite.Value,
+				Sector:         opts.Sector.Value,
+				Product:        opts.Product.Value,
+				ImageTimestamp: timestamp.Format("20060102150405"),
+				ZoomLevel:      opts.zoom.Level,
+				TileXPosition:  x,
+				TileYPosition:  y,
+			})
+			var tile image.Image
+			var err error
+			if opts.CacheDirectory != "" {
+				tile, err = cachedImageDownload(opts, imageTileURL)
+				if err != nil {
+					return nil, fmt.Errorf("unable to get image for timestamp %v: %w", timestamp, err)
+				}
+			} else {
+				tile, err = DownloadImage(imageTileURL)
+				if err != nil {
+					return nil, fmt.Errorf("unable to download image for timestamp %v: %w", timestamp, err)
+				}
+			}
+			position := image.Pt(x-tiles.Min.X, y-tiles.Min.Y).Mul(opts.Sector.TileSize)
+			canvas = imaging.Paste(canvas, tile, position)
+		}
+	}
+	canvas = imaging.Crop(canvas, area.Sub(tiles.Min.Mul(opts.Sector.TileSize)))
+	return transformFrame(opts, canvas), nil
+}
+
+// transformFrame rotates and resizes the composited frame.
+func transformFrame(opts *LoopOptions, canvas *image.NRGBA) *image.NRGBA {
+	if opts.Angle != 0 && opts.FileFormat != GeoPNG {
+		canvas = imaging.Rotate(canvas, opts.Angle, image.Transparent)
+	}
+	if opts.Resize != nil {
+		canvas = resizeFrame(opts.Resize, canvas)
+	}
+	return canvas
+}
+
+// sectorBounds is the area of the full tile canvas at the zoom level that remains after the sector crop.
+func sectorBounds(sector *Sector, zoom *Zoom) image.Rectangle {
+	full := zoom.NumTiles() * sector.TileSize
+	if sector.CropRatioX > 0 && sector.CropRatioY > 0 {
+		w, h := sector.XSize(zoom), sector.YSize(zoom)
+		return image.Rect(0, 0, w, h).Add(image.Pt((full-w)/2, (full-h)/2))
+	}
+	return image.Rect(0, 0, full, full)
+}
+
+// cropArea is the area of the full tile canvas at the zoom level that makes up the final image. The crop is
+// relative to the sector image after the sector crop has been applied.
+func cropArea(sector *Sector, zoom *Zoom, crop *image.Rectangle) image.Rectangle {
+	bounds := sectorBounds(sector, zoom)
+	if crop == nil {
+		return bounds
+	}
+	return crop.Add(bounds.Min).Intersect(bounds)
+}
+
+// tilesCovering is the range of tile positions that cover the area of the full tile canvas.
+func tilesCovering(area image.Rectangle, tileSize int) image.Rectangle {
+	return image.Rect(
+		area.Min.X/tileSize,
+		area.Min.Y/tileSize,
+		(area.Max.X+tileSize-1)/tileSize,
+		(area.Max.Y+tileSize-1)/tileSize,
+	)
+}
+
 func cachedImageDownload(opts *LoopOptions, url string) (image.Image, error) {
 	c := ImageCache{Dir: opts.CacheDirectory}
 	filePath, err := URLToFilePath(url)
@@ -349,12 +772,20 @@ func (s timeSortable) Len() int           { return len(s) }
 
 func makeFileName(opts *LoopOptions, startTime string, endTime string) string {
 	var x, y int
-	if opts.Crop != nil {
-		x = opts.Crop.Dx()
-		y = opts.Crop.Dx()
+	if opts.mosaic != nil {
+		x = opts.mosaic.width
+		y = opts.mosaic.height
+	} else if opts.Track != nil {
+		x = opts.centerSize.X
+		y = opts.centerSize.Y
 	} else {
-		x = opts.Sector.XSize(opts.zoom)
-		y = opts.Sector.YSize(opts.zoom)
+		// Crop areas are clipped to the sector image
+		size := cropArea(opts.Sector, opts.zoom, opts.crop).Size()
+		x, y = size.X, size.Y
+	}
+	if opts.Resize != nil {
+		size := opts.Resize.size(image.Pt(x, y))
+		x, y = size.X, size.Y
 	}
 	return fmt.Sprintf("cira-rammb-slider_%s_%s_%s_%dx%d_%s-%s",
 		opts.Satellite.ID(), opts.Sector.ID(), opts.Product.ID(), x, y, startTime, endTime)
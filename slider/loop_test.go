@@ -18,6 +18,7 @@ package slider
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"image"
 	"testing"
 )
 
@@ -37,3 +38,43 @@ func TestLoopOptsFromURL(t *testing.T) {
 	assert.Zero(t, got.BeginTime, "Incorrect end time")
 	assert.Zero(t, got.EndTime, "Incorrect end time")
 }
+
+func TestCropAreaTiles(t *testing.T) {
+	fullDisk := &Sector{TileSize: 678}
+	conus := &Sector{TileSize: 625, CropRatioX: 1, CropRatioY: 0.6}
+	zoom := &Zoom{Level: 4}
+
+	area := cropArea(fullDisk, zoom, nil)
+	assert.Equal(t, image.Rect(0, 0, 10848, 10848), area)
+	assert.Equal(t, image.Rect(0, 0, 16, 16), tilesCovering(area, fullDisk.TileSize))
+
+	crop := image.Rect(1000, 2000, 1500, 2500)
+	area = cropArea(fullDisk, zoom, &crop)
+	assert.Equal(t, crop, area)
+	assert.Equal(t, image.Rect(1, 2, 3, 4), tilesCovering(area, fullDisk.TileSize))
+
+	// CONUS is cropped around the center of the tile canvas so the crop area is offset by the sector crop
+	area = cropArea(conus, zoom, &crop)
+	assert.Equal(t, image.Rect(1000, 4000, 1500, 4500), area)
+	assert.Equal(t, image.Rect(1, 6, 3, 8), tilesCovering(area, conus.TileSize))
+
+	outside := image.Rect(20000, 20000, 20100, 20100)
+	assert.True(t, cropArea(fullDisk, zoom, &outside).Empty())
+}
+
+func TestMakeFileName(t *testing.T) {
+	satellite := testInventory(t).Satellites["goes-16"]
+	opts := &LoopOptions{
+		Satellite: satellite,
+		Sector:    satellite.Sectors["full-disk"],
+		Product:   satellite.Products["geocolor"],
+		zoom:      satellite.ZoomLevels()[1],
+	}
+	size := opts.Sector.XSize(opts.zoom)
+	opts.crop = &image.Rectangle{Min: image.Pt(100, 200), Max: image.Pt(400, 350)}
+	assert.Equal(t, "cira-rammb-slider_goes-16_full-disk_geocolor_300x150_a-b", makeFileName(opts, "a", "b"))
+
+	// Crop areas clamped at the edge of the sector are named with the size of the clipped area
+	opts.crop = &image.Rectangle{Min: image.Pt(size-100, 0), Max: image.Pt(size+100, 50)}
+	assert.Equal(t, "cira-rammb-slider_goes-16_full-disk_geocolor_100x50_a-b", makeFileName(opts, "a", "b"))
+}
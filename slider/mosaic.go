@@ -0,0 +1,359 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultMosaicBlendWidth is the width in degrees of the seams between satellites that are blended together if no
+// width is given.
+const DefaultMosaicBlendWidth = 5.0
+
+// defaultMosaicBox is the area of a mosaic if no bounding box is given, which covers every latitude seen by
+// geostationary satellites.
+var defaultMosaicBox = BoundingBox{MinLon: -180, MinLat: -81, MaxLon: 180, MaxLat: 81}
+
+// MosaicOptions are the options used to combine the imagery of neighboring satellites into one loop on an
+// equirectangular latitude/longitude grid. The sectors must have LatLonQuery parameters.
+type MosaicOptions struct {
+	// BlendWidth is the width in degrees of the seams where the imagery of neighboring satellites is blended
+	// together. DefaultMosaicBlendWidth is used if BlendWidth is zero.
+	BlendWidth float64
+	// Satellites are the satellites combined with the loop's satellite. The sector and product with the same names
+	// as the loop's are used for each satellite.
+	Satellites []*Satellite
+	// Width is the width of the mosaic in pixels. If Width is zero the width matches the resolution of the loop's
+	// satellite directly below the satellite at the zoom level.
+	Width int
+}
+
+// mosaicGrid is the equirectangular latitude/longitude grid that a mosaic is drawn on. MaxLon is greater than
+// MinLon for grids that cross the antimeridian.
+type mosaicGrid struct {
+	box    BoundingBox
+	width  int
+	height int
+}
+
+// newMosaicGrid returns the grid of the mosaic covering the loop's bounding box.
+func newMosaicGrid(opts *LoopOptions, zoom *Zoom) (*mosaicGrid, error) {
+	box := defaultMosaicBox
+	if opts.BoundingBox != nil {
+		box = *opts.BoundingBox
+	}
+	err := box.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bounding box: %w", err)
+	}
+	if box.MaxLon < box.MinLon {
+		box.MaxLon += 360
+	}
+	width := opts.Mosaic.Width
+	if width < 0 {
+		return nil, fmt.Errorf("mosaic width can't be negative: %d", width)
+	}
+	if width == 0 {
+		projection, err := opts.Sector.Projection()
+		if err != nil {
+			return nil, err
+		}
+		dx, _ := projection.PixelSize(zoom)
+		degrees := radToDeg(dx * projection.Height() / EarthEquatorialRadius)
+		width = int(math.Ceil((box.MaxLon - box.MinLon) / degrees))
+	}
+	height := int(math.Round(float64(width) * (box.MaxLat - box.MinLat) / (box.MaxLon - box.MinLon)))
+	if height < 1 {
+		height = 1
+	}
+	return &mosaicGrid{box: box, width: width, height: height}, nil
+}
+
+// latLon is the latitude and longitude in degrees of the center of the grid pixel.
+func (g *mosaicGrid) latLon(x, y int) (float64, float64) {
+	lon := g.box.MinLon + (float64(x)+0.5)*(g.box.MaxLon-g.box.MinLon)/float64(g.width)
+	lat := g.box.MaxLat - (float64(y)+0.5)*(g.box.MaxLat-g.box.MinLat)/float64(g.height)
+	return lat, normalizeLon(lon)
+}
+
+// mosaicSource is the imagery of a single satellite in a mosaic.
+type mosaicSource struct {
+	opts       *LoopOptions
+	projection *Projection
+	bounds     image.Rectangle
+}
+
+// mosaicSample is the position of a grid pixel in the sector image of a source and the weight of the source when
+// blending. The source isn't used for the pixel if the weight is zero.
+type mosaicSample struct {
+	x, y   float64
+	weight float64
+}
+
+// prepareMosaic checks the loop options and resolves the zoom level and grid of a mosaic.
+func prepareMosaic(opts *LoopOptions) error {
+	if opts.Crop != nil || opts.Center != nil || opts.Track != nil {
+		return fmt.Errorf("mosaics can only be cropped with a bounding box")
+	}
+	if opts.Mosaic.BlendWidth < 0 {
+		return fmt.Errorf("mosaic blend width can't be negative: %v", opts.Mosaic.BlendWidth)
+	}
+	if opts.Resize != nil && opts.Resize.AutoZoom {
+		log.Warn().Msg("Automatic zoom levels don't apply to mosaics and will not be used.")
+	}
+	if (opts.Sector.MaxZoomLevel - opts.Product.ZoomLevelAdjust) < opts.ZoomLevel {
+		return fmt.Errorf("ZoomLevel %d is greater than sector or product max of %d",
+			opts.ZoomLevel, opts.Sector.MaxZoomLevel-opts.Product.ZoomLevelAdjust)
+	}
+	opts.zoom = opts.Satellite.ZoomLevels()[opts.ZoomLevel]
+	grid, err := newMosaicGrid(opts, opts.zoom)
+	if err != nil {
+		return err
+	}
+	opts.mosaic = grid
+	return nil
+}
+
+// mosaicSources returns the sources of the satellites in the mosaic starting with the loop's satellite. Each
+// source uses the loop's zoom level, or the max zoom level of its sector if that is lower.
+func mosaicSources(opts *LoopOptions) ([]*mosaicSource, error) {
+	var sources []*mosaicSource
+	seen := make(map[*Satellite]bool)
+	for _, satellite := range append([]*Satellite{opts.Satellite}, opts.Mosaic.Satellites...) {
+		if seen[satellite] {
+			continue
+		}
+		seen[satellite] = true
+		sector := satellite.Sectors[opts.Sector.ID()]
+		if sector == nil {
+			return nil, fmt.Errorf("satellite '%s' does not have a '%s' sector", satellite.ID(), opts.Sector.ID())
+		}
+		product := satellite.Products[opts.Product.ID()]
+		if product == nil || sector.ProductMissing(product) {
+			return nil, fmt.Errorf("product '%s' is not available for satellite '%s'", opts.Product.ID(),
+				satellite.ID())
+		}
+		projection, err := sector.Projection()
+		if err != nil {
+			return nil, err
+		}
+		level := opts.ZoomLevel
+		if maxLevel := sector.MaxZoomLevel - product.ZoomLevelAdjust; level > maxLevel {
+			log.Debug().Msgf("Using zoom level %d for satellite '%s'", maxLevel, satellite.ID())
+			level = maxLevel
+		}
+		sourceOpts := *opts
+		sourceOpts.Angle = 0
+		sourceOpts.BoundingBox = nil
+		sourceOpts.Mosaic = nil
+		sourceOpts.Product = product
+		sourceOpts.Resize = nil
+		sourceOpts.Satellite = satellite
+		sourceOpts.Sector = sector
+		sourceOpts.zoom = satellite.ZoomLevels()[level]
+		sources = append(sources, &mosaicSource{
+			opts:       &sourceOpts,
+			projection: projection,
+			bounds:     image.Rect(0, 0, sector.XSize(sourceOpts.zoom), sector.YSize(sourceOpts.zoom)),
+		})
+	}
+	return sources, nil
+}
+
+// rowSamples sets the samples of each source for the row of the grid. Each source is weighted by how much further
+// the pixel is from the point below its satellite than from the point below the nearest satellite that sees it, so
+// that the weights fall from 1 to 0 across the blend width on either side of a seam.
+func (g *mosaicGrid) rowSamples(y int, sources []*mosaicSource, blend float64, samples [][]mosaicSample) {
+	angles := make([]float64, len(sources))
+	for x := 0; x < g.width; x++ {
+		lat, lon := g.latLon(x, y)
+		nearest := math.Inf(1)
+		for i, source := range sources {
+			angles[i] = math.Inf(1)
+			px, py, ok := source.projection.LatLonToPixel(lat, lon, source.opts.zoom)
+			if !ok || !image.Pt(int(math.Floor(px)), int(math.Floor(py))).In(source.bounds) {
+				continue
+			}
+			samples[i][x].x, samples[i][x].y = px, py
+			angles[i] = radToDeg(math.Acos(math.Cos(degToRad(lat)) * math.Cos(degToRad(lon-source.projection.Lon0()))))
+			nearest = math.Min(nearest, angles[i])
+		}
+		for i := range sources {
+			samples[i][x].weight = 0
+			if !math.IsInf(angles[i], 1) {
+				samples[i][x].weight = math.Max(0, 1-(angles[i]-nearest)/blend)
+			}
+		}
+	}
+}
+
+// eachRow calls the function with the row samples of every row of the grid. Rows are processed concurrently.
+func (g *mosaicGrid) eachRow(sources []*mosaicSource, blend float64, f func(y int, samples [][]mosaicSample)) {
+	rows := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			samples := make([][]mosaicSample, len(sources))
+			for i := range samples {
+				samples[i] = make([]mosaicSample, g.width)
+			}
+			for y := range rows {
+				g.rowSamples(y, sources, blend, samples)
+				f(y, samples)
+			}
+			wg.Done()
+		}()
+	}
+	for y := 0; y < g.height; y++ {
+		rows <- y
+	}
+	close(rows)
+	wg.Wait()
+}
+
+// mosaicCrops sets the crop area of each source to the part of its sector image used by the grid. Sources that
+// aren't used are removed.
+func mosaicCrops(grid *mosaicGrid, sources []*mosaicSource, blend float64) []*mosaicSource {
+	lock := sync.Mutex{}
+	used := make([]image.Rectangle, len(sources))
+	grid.eachRow(sources, blend, func(y int, samples [][]mosaicSample) {
+		row := make([]image.Rectangle, len(sources))
+		for i := range sources {
+			for _, sample := range samples[i] {
+				if sample.weight > 0 {
+					// The neighboring pixels are also used by bilinear sampling
+					px, py := int(math.Floor(sample.x)), int(math.Floor(sample.y))
+					row[i] = row[i].Union(image.Rect(px-1, py-1, px+2, py+2))
+				}
+			}
+		}
+		lock.Lock()
+		for i := range sources {
+			used[i] = used[i].Union(row[i])
+		}
+		lock.Unlock()
+	})
+	var kept []*mosaicSource
+	for i, source := range sources {
+		crop := used[i].Intersect(source.bounds)
+		if crop.Empty() {
+			log.Warn().Msgf("Satellite '%s' doesn't see any of the mosaic area and will not be used.",
+				source.opts.Satellite.ID())
+			continue
+		}
+		source.opts.crop = &crop
+		kept = append(kept, source)
+	}
+	return kept
+}
+
+// mosaicImages downloads the imagery of each satellite in the mosaic at the capture times nearest to the selected
+// times and reprojects it onto the mosaic grid with the seams between satellites blended together.
+func mosaicImages(opts *LoopOptions, selectedTimes []time.Time) ([]image.Image, error) {
+	blend := opts.Mosaic.BlendWidth
+	if blend == 0 {
+		blend = DefaultMosaicBlendWidth
+	}
+	sources, err := mosaicSources(opts)
+	if err != nil {
+		return nil, err
+	}
+	grid := opts.mosaic
+	sources = mosaicCrops(grid, sources, blend)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("none of the satellites see the mosaic area")
+	}
+
+	frames := make([][]*image.NRGBA, len(sources))
+	for i, source := range sources {
+		times := selectedTimes
+		if source.opts.Satellite != opts.Satellite {
+			times, err = panelCaptureTimes(source.opts, selectedTimes)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get times for satellite '%s': %w", source.opts.Satellite.ID(),
+					err)
+			}
+		}
+		images, err := getImages(source.opts, times)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get images for satellite '%s': %w", source.opts.Satellite.ID(),
+				err)
+		}
+		frames[i] = make([]*image.NRGBA, len(images))
+		for j, img := range images {
+			frames[i][j] = imaging.Clone(img)
+		}
+	}
+
+	mosaics := renderMosaic(grid, sources, frames, blend)
+	images := make([]image.Image, len(mosaics))
+	for j, mosaic := range mosaics {
+		images[j] = transformFrame(opts, mosaic)
+	}
+	return images, nil
+}
+
+// renderMosaic reprojects the frames of each source onto the grid. The frames of each source are cropped to its
+// crop area.
+func renderMosaic(grid *mosaicGrid, sources []*mosaicSource, frames [][]*image.NRGBA, blend float64) []*image.NRGBA {
+	log.Debug().Msgf("Reprojecting %d satellites onto a %dx%d mosaic", len(sources), grid.width, grid.height)
+	timeIn := time.Now()
+	mosaics := make([]*image.NRGBA, len(frames[0]))
+	for j := range mosaics {
+		mosaics[j] = image.NewNRGBA(image.Rect(0, 0, grid.width, grid.height))
+	}
+	grid.eachRow(sources, blend, func(y int, samples [][]mosaicSample) {
+		var pixel [4]uint8
+		for j, mosaic := range mosaics {
+			for x := 0; x < grid.width; x++ {
+				var r, g, b, covered, total float64
+				for i, source := range sources {
+					sample := samples[i][x]
+					if sample.weight == 0 {
+						continue
+					}
+					// Pixel centers are half of a pixel from the pixel edges
+					samplePixel(pixel[:], frames[i][j], sample.x-float64(source.opts.crop.Min.X)-0.5,
+						sample.y-float64(source.opts.crop.Min.Y)-0.5)
+					w := sample.weight * float64(pixel[3]) / 255
+					r, g, b = r+w*float64(pixel[0]), g+w*float64(pixel[1]), b+w*float64(pixel[2])
+					covered += w
+					total += sample.weight
+				}
+				if covered == 0 {
+					continue
+				}
+				k := y*mosaic.Stride + x*4
+				mosaic.Pix[k] = uint8(r/covered + 0.5)
+				mosaic.Pix[k+1] = uint8(g/covered + 0.5)
+				mosaic.Pix[k+2] = uint8(b/covered + 0.5)
+				mosaic.Pix[k+3] = uint8(255*covered/total + 0.5)
+			}
+		}
+	})
+	timeOut := time.Now()
+	log.Debug().Msgf("Reprojection took %.3fs", timeOut.Sub(timeIn).Seconds())
+
+	return mosaics
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testMosaicOptions(t *testing.T) *LoopOptions {
+	inventory := testInventory(t)
+	satellite := inventory.Satellites["goes-16"]
+	return &LoopOptions{
+		BoundingBox: &BoundingBox{MinLon: -150, MinLat: -10, MaxLon: -60, MaxLat: 10},
+		Mosaic:      &MosaicOptions{Satellites: []*Satellite{inventory.Satellites["goes-17"]}, Width: 90},
+		Product:     satellite.Products["geocolor"],
+		Satellite:   satellite,
+		Sector:      satellite.Sectors["full-disk"],
+		ZoomLevel:   0,
+	}
+}
+
+func TestMosaicGrid(t *testing.T) {
+	opts := testMosaicOptions(t)
+	opts.BoundingBox = &BoundingBox{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}
+	opts.Mosaic.Width = 40
+	grid, err := newMosaicGrid(opts, opts.Satellite.ZoomLevels()[0])
+	require.NoError(t, err)
+	assert.Equal(t, 40, grid.width)
+	assert.Equal(t, 40, grid.height)
+
+	lat, lon := grid.latLon(0, 0)
+	assert.InDelta(t, 9.75, lat, 1e-9)
+	assert.InDelta(t, 170.25, lon, 1e-9)
+	_, lon = grid.latLon(39, 0)
+	assert.InDelta(t, -170.25, lon, 1e-9, "Grids should cross the antimeridian")
+
+	// The default width matches the resolution below the satellite
+	opts.BoundingBox = nil
+	opts.Mosaic.Width = 0
+	grid, err = newMosaicGrid(opts, opts.Satellite.ZoomLevels()[0])
+	require.NoError(t, err)
+	assert.InDelta(t, 2500, grid.width, 100)
+	assert.InDelta(t, grid.width*162/360, grid.height, 1)
+}
+
+func TestMosaicSources(t *testing.T) {
+	opts := testMosaicOptions(t)
+	opts.ZoomLevel = 5
+	sources, err := mosaicSources(opts)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	assert.Equal(t, "goes-17", sources[1].opts.Satellite.ID())
+	assert.Equal(t, "full-disk", sources[1].opts.Sector.ID())
+	assert.Nil(t, sources[1].opts.Mosaic)
+
+	inventory := testInventory(t)
+	opts.Mosaic.Satellites = []*Satellite{inventory.Satellites["meteosat-11"]}
+	sources, err = mosaicSources(opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, sources[1].opts.zoom.Level, "Zoom levels should be limited to the sector max")
+
+	opts.Product = opts.Satellite.Products["band-13"]
+	_, err = mosaicSources(opts)
+	assert.Error(t, err, "Products missing from a satellite should fail")
+}
+
+func TestRenderMosaic(t *testing.T) {
+	opts := testMosaicOptions(t)
+	require.NoError(t, prepareMosaic(opts))
+	sources, err := mosaicSources(opts)
+	require.NoError(t, err)
+	sources = mosaicCrops(opts.mosaic, sources, DefaultMosaicBlendWidth)
+	require.Len(t, sources, 2)
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	frames := [][]*image.NRGBA{
+		{imaging.New(sources[0].opts.crop.Dx(), sources[0].opts.crop.Dy(), red)},
+		{imaging.New(sources[1].opts.crop.Dx(), sources[1].opts.crop.Dy(), blue)},
+	}
+	mosaics := renderMosaic(opts.mosaic, sources, frames, DefaultMosaicBlendWidth)
+	require.Len(t, mosaics, 1)
+	assert.Equal(t, image.Rect(0, 0, 90, 20), mosaics[0].Bounds())
+	assert.Equal(t, blue, mosaics[0].NRGBAAt(5, 10), "Pixels near GOES-17 should use GOES-17")
+	assert.Equal(t, red, mosaics[0].NRGBAAt(85, 10), "Pixels near GOES-16 should use GOES-16")
+
+	// The seam is halfway between the satellites
+	seam := int((sources[0].projection.Lon0()+sources[1].projection.Lon0())/2 + 150)
+	pixel := mosaics[0].NRGBAAt(seam, 10)
+	assert.InDelta(t, 128, pixel.R, 40)
+	assert.InDelta(t, 128, pixel.B, 40)
+	assert.Equal(t, uint8(255), pixel.A)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NavigationDirections are the names of the directions that SLIDER can navigate between sectors in, in the order
+// they are listed.
+var NavigationDirections = []string{"up", "right", "down", "left"}
+
+// Direction returns the neighbor in the named direction, such as "left". Nil is returned if the sector has no
+// neighbor in the direction.
+func (n *ProductNavigation) Direction(name string) (*ProductNavigationDirection, error) {
+	if n == nil {
+		return nil, nil
+	}
+	switch strings.ToLower(name) {
+	case "up":
+		return n.Up, nil
+	case "right":
+		return n.Right, nil
+	case "down":
+		return n.Down, nil
+	case "left":
+		return n.Left, nil
+	default:
+		return nil, fmt.Errorf("unknown direction '%s': options are 'up', 'right', 'down', and 'left'", name)
+	}
+}
+
+// Resolve returns the satellite and sector in the inventory that the navigation direction leads to.
+func (d *ProductNavigationDirection) Resolve(inventory *ProductInventory) (*Satellite, *Sector, error) {
+	satelliteID := strings.ReplaceAll(d.Satellite, "_", "-")
+	satellite := inventory.Satellites[satelliteID]
+	if satellite == nil {
+		return nil, nil, fmt.Errorf("satellite '%s' is not in the inventory", satelliteID)
+	}
+	sectorID := strings.ReplaceAll(d.Sector, "_", "-")
+	sector := satellite.Sectors[sectorID]
+	if sector == nil {
+		return nil, nil, fmt.Errorf("sector '%s' is not available for the '%s' satellite", sectorID, satelliteID)
+	}
+	return satellite, sector, nil
+}
+
+// ResolveProduct returns the satellite and sector that the navigation direction leads to along with the product of
+// the same name on that satellite. An error is returned if the product isn't available for the sector.
+func (d *ProductNavigationDirection) ResolveProduct(inventory *ProductInventory, product *Product) (*Satellite,
+	*Sector, *Product, error) {
+	satellite, sector, err := d.Resolve(inventory)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	neighbor := satellite.Products[product.ID()]
+	if neighbor == nil || sector.ProductMissing(neighbor) {
+		return nil, nil, nil, fmt.Errorf("product '%s' is not available for sector '%s' on the '%s' satellite",
+			product.ID(), sector.ID(), satellite.ID())
+	}
+	return satellite, sector, neighbor, nil
+}
+
+// Navigate returns the satellite, sector, and product reached by navigating from the sector in each of the named
+// directions in turn. The product of the same name is used on each satellite and must be available at every step.
+// Products aren't checked if product is nil.
+func (inv *ProductInventory) Navigate(satellite *Satellite, sector *Sector, product *Product,
+	directions ...string) (*Satellite, *Sector, *Product, error) {
+	for _, name := range directions {
+		direction, err := sector.Navigation.Direction(name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if direction == nil {
+			return nil, nil, nil, fmt.Errorf("sector '%s' on the '%s' satellite has no neighbor to the %s",
+				sector.ID(), satellite.ID(), name)
+		}
+		if product != nil {
+			satellite, sector, product, err = direction.ResolveProduct(inv, product)
+		} else {
+			satellite, sector, err = direction.Resolve(inv)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("unable to navigate %s: %w", name, err)
+		}
+	}
+	return satellite, sector, product, nil
+}
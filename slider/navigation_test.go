@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNavigationResolve(t *testing.T) {
+	inventory := testInventory(t)
+	sector := inventory.Satellites["goes-16"].Sectors["full-disk"]
+
+	direction, err := sector.Navigation.Direction("Left")
+	require.NoError(t, err)
+	require.NotNil(t, direction)
+	satellite, neighbor, err := direction.Resolve(inventory)
+	require.NoError(t, err)
+	assert.Equal(t, inventory.Satellites["goes-17"], satellite)
+	assert.Equal(t, inventory.Satellites["goes-17"].Sectors["full-disk"], neighbor)
+
+	_, err = sector.Navigation.Direction("sideways")
+	assert.Error(t, err)
+
+	direction, err = inventory.Satellites["goes-16"].Sectors["conus"].Navigation.Direction("left")
+	require.NoError(t, err)
+	assert.Nil(t, direction, "Sectors without navigation should have no neighbors")
+
+	_, _, err = (&ProductNavigationDirection{Satellite: "goes_99", Sector: "full_disk"}).Resolve(inventory)
+	assert.Error(t, err)
+}
+
+func TestNavigate(t *testing.T) {
+	inventory := testInventory(t)
+	goes16 := inventory.Satellites["goes-16"]
+	sector := goes16.Sectors["full-disk"]
+
+	satellite, neighbor, product, err := inventory.Navigate(goes16, sector, goes16.Products["geocolor"], "left")
+	require.NoError(t, err)
+	assert.Equal(t, "goes-17", satellite.ID())
+	assert.Equal(t, "full-disk", neighbor.ID())
+	assert.Equal(t, inventory.Satellites["goes-17"].Products["geocolor"], product)
+
+	// Walking left around the globe returns to the start
+	satellite, neighbor, _, err = inventory.Navigate(goes16, sector, nil, "left", "left", "left", "left", "left")
+	require.NoError(t, err)
+	assert.Equal(t, goes16, satellite)
+	assert.Equal(t, sector, neighbor)
+
+	satellite, _, _, err = inventory.Navigate(goes16, sector, nil, "up", "down")
+	require.NoError(t, err)
+	assert.Equal(t, "goes-17", satellite.ID())
+
+	_, _, _, err = inventory.Navigate(goes16, sector, goes16.Products["band-13"], "right")
+	assert.Error(t, err, "Products missing from the neighbor should fail")
+	_, _, _, err = inventory.Navigate(goes16, goes16.Sectors["conus"], nil, "left")
+	assert.Error(t, err)
+}
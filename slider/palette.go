@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/andybons/gogif"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// maxPaletteSamples is the maximum number of pixels sampled from the frames of an animation to compute a palette.
+const maxPaletteSamples = 1 << 18
+
+// samplePalette computes a palette of up to numColors colors from pixels sampled evenly across all of the images.
+func samplePalette(images []image.Image, numColors int) color.Palette {
+	var total int
+	for _, img := range images {
+		total += img.Bounds().Dx() * img.Bounds().Dy()
+	}
+	step := total/maxPaletteSamples + 1
+
+	// Every step-th pixel is sampled counting across all of the images
+	var samples []color.Color
+	var base int
+	for _, img := range images {
+		bounds := img.Bounds()
+		pixels := bounds.Dx() * bounds.Dy()
+		for k := (step - base%step) % step; k < pixels; k += step {
+			samples = append(samples, img.At(bounds.Min.X+k%bounds.Dx(), bounds.Min.Y+k/bounds.Dx()))
+		}
+		base += pixels
+	}
+
+	sample := image.NewNRGBA(image.Rect(0, 0, len(samples), 1))
+	for i, c := range samples {
+		sample.Set(i, 0, c)
+	}
+	quantized := image.NewPaletted(sample.Bounds(), nil)
+	quantizer := gogif.MedianCutQuantizer{NumColor: numColors}
+	quantizer.Quantize(quantized, sample.Bounds(), sample, image.Point{})
+	return quantized.Palette
+}
+
+// quantizeFrame reduces the image to the palette. If palette is nil a palette of up to numColors colors is computed
+// for the image. Dither enables Floyd-Steinberg dithering.
+func quantizeFrame(img image.Image, palette color.Palette, numColors int, dither bool) *image.Paletted {
+	if palette == nil {
+		if !dither {
+			paletted := image.NewPaletted(img.Bounds(), nil)
+			quantizer := gogif.MedianCutQuantizer{NumColor: numColors}
+			quantizer.Quantize(paletted, img.Bounds(), img, image.Point{})
+			return paletted
+		}
+		palette = samplePalette([]image.Image{img}, numColors)
+	}
+	paletted := image.NewPaletted(img.Bounds(), palette)
+	var drawer draw.Drawer = draw.Src
+	if dither {
+		drawer = draw.FloydSteinberg
+	}
+	drawer.Draw(paletted, paletted.Bounds(), img, img.Bounds().Min)
+	return paletted
+}
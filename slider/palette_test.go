@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// gradientFrames returns frames with a horizontal gray gradient that shifts by the offset in each frame.
+func gradientFrames(count, offset int) []image.Image {
+	images := make([]image.Image, count)
+	for i := range images {
+		img := image.NewNRGBA(image.Rect(0, 0, 256, 32))
+		for x := 0; x < 256; x++ {
+			for y := 0; y < 32; y++ {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(x + i*offset), G: uint8(x), B: 128, A: 255})
+			}
+		}
+		images[i] = img
+	}
+	return images
+}
+
+func TestSamplePalette(t *testing.T) {
+	red := image.NewUniform(color.NRGBA{R: 255, A: 255})
+	blue := image.NewUniform(color.NRGBA{B: 255, A: 255})
+	images := []image.Image{
+		image.NewNRGBA(image.Rect(0, 0, 10, 10)),
+		image.NewNRGBA(image.Rect(0, 0, 10, 10)),
+	}
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			images[0].(*image.NRGBA).Set(x, y, red)
+			images[1].(*image.NRGBA).Set(x, y, blue)
+		}
+	}
+	palette := samplePalette(images, 16)
+	assert.Len(t, palette, 2, "Palette should contain colors from every image")
+
+	palette = samplePalette(gradientFrames(4, 10), 32)
+	assert.Len(t, palette, 32)
+}
+
+func TestAnimateGIFGlobalPalette(t *testing.T) {
+	images := gradientFrames(4, 10)
+	animation, err := AnimateGIFWithOptions(images, 10, ForwardLoop, &GIFOptions{GlobalPalette: true, Dither: true,
+		NumColors: 64})
+	require.NoError(t, err)
+	palette, ok := animation.Config.ColorModel.(color.Palette)
+	require.True(t, ok, "Global palette should be set")
+	assert.Len(t, palette, 64, "Palette should include the transparent color")
+	for _, frame := range animation.Image {
+		assert.Equal(t, palette, frame.Palette, "Every frame should use the global palette")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, animation))
+	decoded, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, 4)
+	assert.Equal(t, 256, decoded.Config.Width)
+}
+
+func TestQuantizeFrameDither(t *testing.T) {
+	img := gradientFrames(1, 0)[0]
+	palette := color.Palette{color.NRGBA{G: 0, R: 0, B: 128, A: 255}, color.NRGBA{R: 255, G: 255, B: 128, A: 255}}
+	plain := quantizeFrame(img, palette, 2, false)
+	dithered := quantizeFrame(img, palette, 2, true)
+
+	// Without dithering the middle of the gradient is a solid color but dithering mixes both colors
+	mixed := map[uint8]bool{}
+	for x := 112; x < 144; x++ {
+		mixed[dithered.ColorIndexAt(x, 16)] = true
+	}
+	assert.Len(t, mixed, 2)
+	assert.Equal(t, plain.ColorIndexAt(120, 16), plain.ColorIndexAt(126, 16))
+}
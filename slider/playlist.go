@@ -0,0 +1,193 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+	"image"
+	"io/ioutil"
+	"time"
+)
+
+// Playlist is a YAML description of the segments of a storyline. Satellites, sectors, and products are given by ID
+// and times use the format YYYYMMDDhhmmss.
+type Playlist struct {
+	// Crossfade is the number of frames blended between segments.
+	Crossfade int `yaml:"crossfade"`
+	// Width and Height are the size of the storyline frames in pixels. The size of the first segment's frames is
+	// used if they aren't set.
+	Height   int               `yaml:"height"`
+	Segments []PlaylistSegment `yaml:"segments"`
+	// Title is the title of the storyline.
+	Title string `yaml:"title"`
+	// TitleFrames is the number of frames that each title card is shown for.
+	TitleFrames int `yaml:"title_frames"`
+	Width       int `yaml:"width"`
+}
+
+// PlaylistSegment describes the loop of a single storyline segment. The options of the base loop are used for any
+// options that aren't set.
+type PlaylistSegment struct {
+	// Begin is the capture time of the first image in the segment. This can't be used with End.
+	Begin string `yaml:"begin"`
+	// BoundingBox is the geographic area to crop to as minLon, minLat, maxLon, maxLat in degrees.
+	BoundingBox []float64 `yaml:"bbox"`
+	// End is the capture time of the last image in the segment. This can't be used with Begin.
+	End string `yaml:"end"`
+	// Images is the number of images in the segment.
+	Images int `yaml:"images"`
+	// Loop is the direction the segment is played in. Options are forward, reverse, and rock.
+	Loop      string `yaml:"loop"`
+	Product   string `yaml:"product"`
+	Satellite string `yaml:"satellite"`
+	Sector    string `yaml:"sector"`
+	// TimeStep is the interval between image capture times in minutes.
+	TimeStep int `yaml:"time_step"`
+	// Title is drawn on a title card shown before the segment.
+	Title string `yaml:"title"`
+	Zoom  *int   `yaml:"zoom"`
+}
+
+// LoadPlaylist reads a YAML playlist file.
+func LoadPlaylist(filePath string) (*Playlist, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read playlist file: %w", err)
+	}
+	return ParsePlaylist(data)
+}
+
+// ParsePlaylist parses a YAML playlist. Unknown keys are rejected so that misspelled options aren't ignored.
+func ParsePlaylist(data []byte) (*Playlist, error) {
+	playlist := &Playlist{}
+	err := yaml.UnmarshalStrict(data, playlist)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse playlist: %w", err)
+	}
+	if len(playlist.Segments) == 0 {
+		return nil, fmt.Errorf("playlist has no segments")
+	}
+	if (playlist.Width == 0) != (playlist.Height == 0) {
+		return nil, fmt.Errorf("playlist width and height must be set together")
+	}
+	return playlist, nil
+}
+
+// Storyline creates the storyline options for the playlist. Each segment starts from a copy of the base loop
+// options, which also provide the output options of the storyline.
+func (p *Playlist) Storyline(inventory *ProductInventory, base *LoopOptions) (*StorylineOptions, error) {
+	story := &StorylineOptions{
+		Crossfade:   p.Crossfade,
+		Size:        image.Pt(p.Width, p.Height),
+		Title:       p.Title,
+		TitleFrames: p.TitleFrames,
+	}
+	for i, segment := range p.Segments {
+		opts, err := segment.loopOptions(inventory, base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid segment %d: %w", i+1, err)
+		}
+		story.Segments = append(story.Segments, &StorylineSegment{Loop: opts, Title: segment.Title})
+	}
+	return story, nil
+}
+
+// loopOptions creates the options of the segment's loop from a copy of the base loop options.
+func (s *PlaylistSegment) loopOptions(inventory *ProductInventory, base *LoopOptions) (*LoopOptions, error) {
+	opts := *base
+	satelliteID, sectorID, productID := s.Satellite, s.Sector, s.Product
+	if satelliteID == "" && base.Satellite != nil {
+		satelliteID = base.Satellite.ID()
+	}
+	if sectorID == "" && base.Sector != nil {
+		sectorID = base.Sector.ID()
+	}
+	if productID == "" && base.Product != nil {
+		productID = base.Product.ID()
+	}
+	if satelliteID == "" || sectorID == "" || productID == "" {
+		return nil, fmt.Errorf("a satellite, sector, and product must be set")
+	}
+	opts.Satellite = inventory.Satellites[satelliteID]
+	if opts.Satellite == nil {
+		return nil, fmt.Errorf("'%s' is not a valid satellite", satelliteID)
+	}
+	opts.Sector = opts.Satellite.Sectors[sectorID]
+	if opts.Sector == nil {
+		return nil, fmt.Errorf("'%s' is not a valid sector for the '%s' satellite", sectorID, satelliteID)
+	}
+	opts.Product = opts.Satellite.Products[productID]
+	if opts.Product == nil || opts.Sector.ProductMissing(opts.Product) {
+		return nil, fmt.Errorf("'%s' is not a valid sector product for the '%s' satellite", productID, satelliteID)
+	}
+	if opts.Legend != nil && opts.Product.ColorTableName == "" {
+		log.Warn().Msgf("Product '%s' does not have a color table -- no legend will be attached.",
+			opts.Product.ID())
+		opts.Legend = nil
+	}
+
+	if s.Zoom != nil {
+		opts.ZoomLevel = *s.Zoom
+	}
+	if s.Images != 0 {
+		opts.NumberOfImages = s.Images
+	}
+	if s.TimeStep != 0 {
+		opts.TimeStep = s.TimeStep
+	}
+	err := s.setTimeWindow(&opts)
+	if err != nil {
+		return nil, err
+	}
+	if s.Loop != "" {
+		opts.Loop, err = ParseLoopStyle(s.Loop)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.BoundingBox != nil {
+		if len(s.BoundingBox) != 4 {
+			return nil, fmt.Errorf("bbox must have exactly 4 values: %v", s.BoundingBox)
+		}
+		opts.BoundingBox = &BoundingBox{MinLon: s.BoundingBox[0], MinLat: s.BoundingBox[1],
+			MaxLon: s.BoundingBox[2], MaxLat: s.BoundingBox[3]}
+		opts.Crop, opts.Center, opts.Track = nil, nil, nil
+	}
+	return &opts, nil
+}
+
+// setTimeWindow replaces the time window of the loop options with the segment's begin or end time if either is set.
+func (s *PlaylistSegment) setTimeWindow(opts *LoopOptions) error {
+	if s.Begin == "" && s.End == "" {
+		return nil
+	}
+	if s.Begin != "" && s.End != "" {
+		return fmt.Errorf("begin and end can't be used together")
+	}
+	opts.BeginTime, opts.EndTime = time.Time{}, time.Time{}
+	var err error
+	if s.Begin != "" {
+		opts.BeginTime, err = time.Parse("20060102150405", s.Begin)
+	} else {
+		opts.EndTime, err = time.Parse("20060102150405", s.End)
+	}
+	if err != nil {
+		return fmt.Errorf("times must use the format YYYYMMDDhhmmss: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestParsePlaylist(t *testing.T) {
+	data := []byte(`
+crossfade: 6
+title: Hurricane Ida
+width: 1280
+height: 720
+segments:
+  - title: Full Disk
+    sector: full-disk
+    images: 12
+  - sector: conus
+    zoom: 0
+    loop: rock
+    begin: "20210829120000"
+    bbox: [-98, 18, -80, 31]
+`)
+	playlist, err := ParsePlaylist(data)
+	require.NoError(t, err)
+	assert.Equal(t, 6, playlist.Crossfade)
+	assert.Equal(t, "Hurricane Ida", playlist.Title)
+	require.Len(t, playlist.Segments, 2)
+	assert.Equal(t, "Full Disk", playlist.Segments[0].Title)
+	assert.Nil(t, playlist.Segments[0].Zoom)
+	require.NotNil(t, playlist.Segments[1].Zoom)
+	assert.Equal(t, 0, *playlist.Segments[1].Zoom)
+	assert.Equal(t, []float64{-98, 18, -80, 31}, playlist.Segments[1].BoundingBox)
+
+	_, err = ParsePlaylist([]byte("segments:\n  - sectr: conus\n"))
+	assert.Error(t, err, "Unknown keys should fail")
+	_, err = ParsePlaylist([]byte("crossfade: 2\n"))
+	assert.Error(t, err, "Playlists without segments should fail")
+	_, err = ParsePlaylist([]byte("width: 100\nsegments:\n  - sector: conus\n"))
+	assert.Error(t, err, "Width without height should fail")
+}
+
+func TestPlaylistStoryline(t *testing.T) {
+	inventory := testInventory(t)
+	satellite := inventory.Satellites["goes-16"]
+	base := &LoopOptions{
+		Satellite:      satellite,
+		Sector:         satellite.Sectors["full-disk"],
+		Product:        satellite.Products["geocolor"],
+		Crop:           &image.Rectangle{Max: image.Pt(100, 100)},
+		EndTime:        time.Date(2021, 8, 29, 18, 0, 0, 0, time.UTC),
+		Legend:         &LegendOptions{},
+		NumberOfImages: 6,
+		Speed:          15,
+		ZoomLevel:      1,
+	}
+	zoom := 0
+	playlist := &Playlist{
+		Crossfade: 4,
+		Title:     "Hurricane Ida",
+		Width:     640,
+		Height:    360,
+		Segments: []PlaylistSegment{
+			{Title: "Full Disk", Images: 12},
+			{Sector: "conus", Product: "band-13", Zoom: &zoom, Loop: "rock", Begin: "20210829120000",
+				BoundingBox: []float64{-98, 18, -80, 31}},
+			{Satellite: "goes-17", Sector: "full-disk"},
+		},
+	}
+	story, err := playlist.Storyline(inventory, base)
+	require.NoError(t, err)
+	assert.Equal(t, 4, story.Crossfade)
+	assert.Equal(t, "Hurricane Ida", story.Title)
+	assert.Equal(t, image.Pt(640, 360), story.Size)
+	require.Len(t, story.Segments, 3)
+
+	first := story.Segments[0]
+	assert.Equal(t, "Full Disk", first.Title)
+	assert.Equal(t, 12, first.Loop.NumberOfImages)
+	assert.Equal(t, base.Crop, first.Loop.Crop, "Segments should inherit the base options")
+	assert.Nil(t, first.Loop.Legend, "Legends should be dropped for products without a color table")
+
+	second := story.Segments[1].Loop
+	assert.Equal(t, "conus", second.Sector.ID())
+	assert.Equal(t, "band-13", second.Product.ID())
+	assert.Equal(t, 0, second.ZoomLevel)
+	assert.Equal(t, RockLoop, second.Loop)
+	assert.Equal(t, time.Date(2021, 8, 29, 12, 0, 0, 0, time.UTC), second.BeginTime)
+	assert.True(t, second.EndTime.IsZero(), "The segment's time window should replace the base time window")
+	assert.Equal(t, &BoundingBox{MinLon: -98, MinLat: 18, MaxLon: -80, MaxLat: 31}, second.BoundingBox)
+	assert.Nil(t, second.Crop)
+	assert.NotNil(t, second.Legend)
+
+	third := story.Segments[2].Loop
+	assert.Equal(t, "goes-17", third.Satellite.ID())
+	assert.Equal(t, "geocolor", third.Product.ID(), "The base product should be found on the segment's satellite")
+	assert.Equal(t, 1, third.ZoomLevel)
+	assert.Equal(t, 6, base.NumberOfImages, "The base options shouldn't be changed")
+
+	for _, segment := range []PlaylistSegment{
+		{Satellite: "goes-99"},
+		{Sector: "nowhere"},
+		{Product: "band-99"},
+		{Loop: "sideways"},
+		{Begin: "yesterday"},
+		{Begin: "20210829120000", End: "20210829180000"},
+		{BoundingBox: []float64{-98, 18}},
+	} {
+		_, err = (&Playlist{Segments: []PlaylistSegment{segment}}).Storyline(inventory, base)
+		assert.Error(t, err, "%+v should fail", segment)
+	}
+	_, err = (&Playlist{Segments: []PlaylistSegment{{}}}).Storyline(inventory, &LoopOptions{})
+	assert.Error(t, err, "Segments without a satellite, sector, and product should fail")
+}
@@ -126,6 +126,9 @@ func ParseProductsJS(data []byte) (*ProductInventory, error) {
 				sector.CropRatioX = cropSettings.RatioX
 				sector.CropRatioY = cropSettings.RatioY
 			}
+			if sector.LatLonQuery != nil {
+				sector.LatLonQuery.SweepY = sweepsY(sat.ID())
+			}
 			newSectors[sector.ID()] = sector
 		}
 		sat.Sectors = newSectors
@@ -144,6 +147,11 @@ func ParseProductsJS(data []byte) (*ProductInventory, error) {
 	return inventory, nil
 }
 
+// sweepsY returns true if the satellite sweeps along the Y-axis. Only the GOES satellites sweep along the X-axis.
+func sweepsY(satelliteID string) bool {
+	return !strings.HasPrefix(satelliteID, "goes-")
+}
+
 // GetProductInventory will download the latest products from SLIDER or return the builtin fail-safe product
 // inventory if the latest products cannot be downloaded.
 func GetProductInventory() (*ProductInventory, error) {
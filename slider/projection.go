@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthEquatorialRadius is the GRS80 semi-major axis of the Earth in kilometers.
+const EarthEquatorialRadius = 6378.137
+
+// EarthPolarRadius is the GRS80 semi-minor axis of the Earth in kilometers.
+const EarthPolarRadius = 6356.75231414
+
+// LatLonQuery contains the parameters SLIDER uses to convert between image pixels and latitude/longitude for a
+// sector imaged by a geostationary satellite.
+type LatLonQuery struct {
+	// Lon0 is the longitude of the sub-satellite point in degrees.
+	Lon0 float64 `json:"lon0"`
+	// SatAlt is the distance from the center of the Earth to the satellite in kilometers.
+	SatAlt float64 `json:"sat_alt"`
+	// MaxRadX is the scan angle in radians from the sub-satellite point to the edge of the disk along the X-axis.
+	MaxRadX float64 `json:"max_rad_x"`
+	// MaxRadY is the scan angle in radians from the sub-satellite point to the edge of the disk along the Y-axis.
+	MaxRadY float64 `json:"max_rad_y"`
+	// DiskRadiusXZ0 is the radius of the disk along the X-axis in pixels at zoom level 0.
+	DiskRadiusXZ0 float64 `json:"disk_radius_x_z0"`
+	// DiskRadiusYZ0 is the radius of the disk along the Y-axis in pixels at zoom level 0.
+	DiskRadiusYZ0 float64 `json:"disk_radius_y_z0"`
+	// DecimalPlaces is the number of decimal places SLIDER displays latitude/longitude values with.
+	DecimalPlaces int `json:"decimal_places"`
+	// SweepY is true for satellites that sweep along the Y-axis, such as Himawari and Meteosat, which use the CGMS
+	// convention. GOES satellites sweep along the X-axis. SweepY is set from the satellite by ParseProductsJS.
+	SweepY bool `json:"-"`
+}
+
+// Projection converts between latitude/longitude and pixel coordinates for a sector using the geostationary fixed grid
+// navigation described in the GOES-R Product User Guide, or the CGMS navigation if the satellite sweeps along the
+// Y-axis. Pixel coordinates are measured from the upper-left-hand corner of the sector image after the sector crop (see
+// Sector.XSize and Sector.YSize) has been applied, which is the same coordinate system used by LoopOptions.Crop.
+type Projection struct {
+	query  *LatLonQuery
+	sector *Sector
+}
+
+// Projection returns the geostationary projection for this sector. An error is returned if the sector doesn't
+// include valid LatLonQuery parameters.
+func (s *Sector) Projection() (*Projection, error) {
+	q := s.LatLonQuery
+	if q == nil {
+		return nil, fmt.Errorf("sector '%s' does not support latitude/longitude lookups", s.ID())
+	}
+	if q.SatAlt <= EarthEquatorialRadius {
+		return nil, fmt.Errorf("sector '%s' has an invalid satellite altitude: %v", s.ID(), q.SatAlt)
+	}
+	if q.MaxRadX <= 0 || q.MaxRadY <= 0 || q.DiskRadiusXZ0 <= 0 || q.DiskRadiusYZ0 <= 0 {
+		return nil, fmt.Errorf("sector '%s' has invalid disk dimensions", s.ID())
+	}
+	return &Projection{query: q, sector: s}, nil
+}
+
+// Lon0 is the longitude of the sub-satellite point in degrees.
+func (p *Projection) Lon0() float64 {
+	return p.query.Lon0
+}
+
+// Height is the distance from the surface of the Earth at the equator to the satellite in kilometers.
+func (p *Projection) Height() float64 {
+	return p.query.SatAlt - EarthEquatorialRadius
+}
+
+// ScanAngle converts a latitude and longitude in degrees to the satellite's X/Y scan angles in radians. The returned
+// bool is false if the point isn't visible from the satellite.
+func (p *Projection) ScanAngle(lat, lon float64) (float64, float64, bool) {
+	h := p.query.SatAlt
+	rr := (EarthEquatorialRadius * EarthEquatorialRadius) / (EarthPolarRadius * EarthPolarRadius)
+	e2 := 1 - 1/rr
+
+	phi := degToRad(lat)
+	lambda := degToRad(normalizeLon(lon - p.query.Lon0))
+	phiC := math.Atan(math.Tan(phi) / rr)
+	rC := EarthPolarRadius / math.Sqrt(1-e2*math.Cos(phiC)*math.Cos(phiC))
+
+	sx := h - rC*math.Cos(phiC)*math.Cos(lambda)
+	sy := -rC * math.Cos(phiC) * math.Sin(lambda)
+	sz := rC * math.Sin(phiC)
+	if h*(h-sx) < sy*sy+rr*sz*sz {
+		// The point is on the far side of the Earth
+		return 0, 0, false
+	}
+
+	if p.query.SweepY {
+		return math.Atan(-sy / sx), math.Asin(sz / math.Sqrt(sx*sx+sy*sy+sz*sz)), true
+	}
+	return math.Asin(-sy / math.Sqrt(sx*sx+sy*sy+sz*sz)), math.Atan(sz / sx), true
+}
+
+// LatLon converts the satellite's X/Y scan angles in radians to a latitude and longitude in degrees. The returned
+// bool is false if the scan angles don't intersect the Earth.
+func (p *Projection) LatLon(x, y float64) (float64, float64, bool) {
+	h := p.query.SatAlt
+	rr := (EarthEquatorialRadius * EarthEquatorialRadius) / (EarthPolarRadius * EarthPolarRadius)
+
+	// The unit vector from the satellite along the scan angles
+	dx, dy, dz := math.Cos(x)*math.Cos(y), -math.Sin(x), math.Cos(x)*math.Sin(y)
+	if p.query.SweepY {
+		dy, dz = -math.Sin(x)*math.Cos(y), math.Sin(y)
+	}
+	a := dx*dx + dy*dy + rr*dz*dz
+	b := -2 * h * dx
+	c := h*h - EarthEquatorialRadius*EarthEquatorialRadius
+	d := b*b - 4*a*c
+	if d < 0 {
+		return 0, 0, false
+	}
+	rs := (-b - math.Sqrt(d)) / (2 * a)
+
+	sx := rs * dx
+	sy := rs * dy
+	sz := rs * dz
+
+	lat := math.Atan(rr * sz / math.Sqrt((h-sx)*(h-sx)+sy*sy))
+	lon := degToRad(p.query.Lon0) - math.Atan(sy/(h-sx))
+	return radToDeg(lat), normalizeLon(radToDeg(lon)), true
+}
+
+// PixelSize is the width and height of a single pixel at the zoom level in scan angle radians.
+func (p *Projection) PixelSize(zoom *Zoom) (float64, float64) {
+	n := float64(zoom.NumTiles())
+	return p.query.MaxRadX / (p.query.DiskRadiusXZ0 * n), p.query.MaxRadY / (p.query.DiskRadiusYZ0 * n)
+}
+
+// LatLonToPixel converts a latitude and longitude in degrees to a pixel position in the sector image at the zoom
+// level. The returned bool is false if the point isn't visible from the satellite.
+func (p *Projection) LatLonToPixel(lat, lon float64, zoom *Zoom) (float64, float64, bool) {
+	x, y, ok := p.ScanAngle(lat, lon)
+	if !ok {
+		return 0, 0, false
+	}
+	cx, cy := p.origin(zoom)
+	dx, dy := p.PixelSize(zoom)
+	return cx + x/dx, cy - y/dy, true
+}
+
+// PixelToLatLon converts a pixel position in the sector image at the zoom level to a latitude and longitude in
+// degrees. The returned bool is false if the pixel isn't on the Earth's disk.
+func (p *Projection) PixelToLatLon(px, py float64, zoom *Zoom) (float64, float64, bool) {
+	cx, cy := p.origin(zoom)
+	dx, dy := p.PixelSize(zoom)
+	return p.LatLon((px-cx)*dx, (cy-py)*dy)
+}
+
+// origin is the pixel position of the sub-satellite point in the sector image at the zoom level.
+func (p *Projection) origin(zoom *Zoom) (float64, float64) {
+	full := float64(zoom.NumTiles() * p.sector.TileSize)
+	bounds := sectorBounds(p.sector, zoom)
+	return full/2 - float64(bounds.Min.X), full/2 - float64(bounds.Min.Y)
+}
+
+// normalizeLon wraps a longitude in degrees to the range [-180, 180).
+func normalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math"
+	"testing"
+)
+
+func testInventory(t *testing.T) *ProductInventory {
+	data, err := ioutil.ReadFile("testdata/define-products.js")
+	require.NoError(t, err)
+	inventory, err := ParseProductsJS(data)
+	require.NoError(t, err)
+	return inventory
+}
+
+func TestProjectionScanAngle(t *testing.T) {
+	// Example 5.1.2.8.2 from the GOES-R Product User Guide
+	sector := &Sector{
+		TileSize: 678,
+		LatLonQuery: &LatLonQuery{
+			Lon0:          -75,
+			SatAlt:        42164.16,
+			MaxRadX:       0.151844,
+			MaxRadY:       0.151844,
+			DiskRadiusXZ0: 339,
+			DiskRadiusYZ0: 339,
+		},
+	}
+	p, err := sector.Projection()
+	require.NoError(t, err)
+
+	x, y, ok := p.ScanAngle(33.846162, -84.690932)
+	require.True(t, ok)
+	assert.InDelta(t, -0.024052, x, 1e-6)
+	assert.InDelta(t, 0.095340, y, 1e-6)
+
+	lat, lon, ok := p.LatLon(-0.024052, 0.095340)
+	require.True(t, ok)
+	assert.InDelta(t, 33.846162, lat, 1e-3)
+	assert.InDelta(t, -84.690932, lon, 1e-3)
+
+	_, _, ok = p.ScanAngle(0, 105)
+	assert.False(t, ok, "Point on the far side of the Earth should not be visible")
+	_, _, ok = p.LatLon(0.2, 0)
+	assert.False(t, ok, "Scan angle beyond the limb should not intersect the Earth")
+}
+
+func TestProjectionSweepY(t *testing.T) {
+	himawari := testInventory(t).Satellites["himawari"].Sectors["full-disk"]
+	require.True(t, himawari.LatLonQuery.SweepY)
+	p, err := himawari.Projection()
+	require.NoError(t, err)
+	query := *himawari.LatLonQuery
+	query.SweepY = false
+	sweepX, err := (&Sector{TileSize: himawari.TileSize, LatLonQuery: &query}).Projection()
+	require.NoError(t, err)
+
+	// Both conventions point along the same line of sight but measure the angles around different axes
+	xs, ys, ok := sweepX.ScanAngle(35.68, 170)
+	require.True(t, ok)
+	x, y, ok := p.ScanAngle(35.68, 170)
+	require.True(t, ok)
+	assert.InDelta(t, math.Atan(math.Tan(xs)/math.Cos(ys)), x, 1e-12)
+	assert.InDelta(t, math.Asin(math.Cos(xs)*math.Sin(ys)), y, 1e-12)
+	assert.Greater(t, math.Abs(x-xs), 1e-4, "Angles should differ away from the axes")
+
+	lat, lon, ok := p.LatLon(x, y)
+	require.True(t, ok)
+	assert.InDelta(t, 35.68, lat, 1e-9)
+	assert.InDelta(t, 170, lon, 1e-9)
+
+	x, _, ok = p.ScanAngle(0, 160)
+	require.True(t, ok)
+	xs, _, _ = sweepX.ScanAngle(0, 160)
+	assert.InDelta(t, xs, x, 1e-12, "Angles should match along the equator")
+	assert.False(t, testInventory(t).Satellites["goes-16"].Sectors["full-disk"].LatLonQuery.SweepY)
+}
+
+func TestProjectionPixels(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["full-disk"]
+	p, err := sector.Projection()
+	require.NoError(t, err)
+
+	x, y, ok := p.LatLonToPixel(0, -75, &Zoom{Level: 0})
+	require.True(t, ok)
+	assert.InDelta(t, 339, x, 1e-9)
+	assert.InDelta(t, 339, y, 1e-9)
+
+	x, y, ok = p.LatLonToPixel(0, -75, &Zoom{Level: 2})
+	require.True(t, ok)
+	assert.InDelta(t, 1356, x, 1e-9)
+	assert.InDelta(t, 1356, y, 1e-9)
+
+	// North and west of the sub-satellite point are up and to the left
+	x, y, ok = p.LatLonToPixel(25.76, -80.19, &Zoom{Level: 3})
+	require.True(t, ok)
+	assert.Less(t, x, float64(2712))
+	assert.Less(t, y, float64(2712))
+
+	lat, lon, ok := p.PixelToLatLon(x, y, &Zoom{Level: 3})
+	require.True(t, ok)
+	assert.InDelta(t, 25.76, lat, 1e-6)
+	assert.InDelta(t, -80.19, lon, 1e-6)
+
+	_, _, ok = p.PixelToLatLon(0, 0, &Zoom{Level: 0})
+	assert.False(t, ok, "Corner of the image should be off of the disk")
+}
+
+func TestProjectionMissing(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["conus"]
+	_, err := sector.Projection()
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"math"
+	"strings"
+)
+
+// ResizeOptions are the options used to resize each frame of an animation. The aspect ratio of the frames is always
+// preserved.
+type ResizeOptions struct {
+	// AutoZoom downloads imagery at the smallest zoom level that is at least the target size instead of ZoomLevel.
+	// Crop and CenterSize are still given in pixels at ZoomLevel and are scaled to the chosen zoom level.
+	AutoZoom bool
+	// Filter is the resampling filter used to resize frames. Lanczos is used if Filter is the zero value.
+	Filter imaging.ResampleFilter
+	// Height is the height of the resized frames. If Width is also set the frames are resized to fit within Width
+	// and Height.
+	Height int
+	// MaxDimension is the maximum width and height of the resized frames. Frames that are already smaller are not
+	// enlarged.
+	MaxDimension int
+	// Width is the width of the resized frames. If Height is also set the frames are resized to fit within Width
+	// and Height.
+	Width int
+}
+
+// ParseResampleFilter parses the name of a resampling filter. The options are "lanczos", "catmullrom", and
+// "nearest".
+func ParseResampleFilter(name string) (imaging.ResampleFilter, error) {
+	switch strings.ToLower(name) {
+	case "lanczos":
+		return imaging.Lanczos, nil
+	case "catmullrom":
+		return imaging.CatmullRom, nil
+	case "nearest", "nearestneighbor":
+		return imaging.NearestNeighbor, nil
+	default:
+		return imaging.ResampleFilter{}, fmt.Errorf("unknown resampling filter '%s': options are 'lanczos', "+
+			"'catmullrom', and 'nearest'", name)
+	}
+}
+
+// scale is the factor a frame of the size is scaled by to fit the Width and Height.
+func (r *ResizeOptions) scale(size image.Point) float64 {
+	if size.X <= 0 || size.Y <= 0 {
+		return 1
+	}
+	scale := math.Inf(1)
+	if r.Width > 0 {
+		scale = float64(r.Width) / float64(size.X)
+	}
+	if r.Height > 0 {
+		scale = math.Min(scale, float64(r.Height)/float64(size.Y))
+	}
+	if math.IsInf(scale, 1) {
+		return 1
+	}
+	return scale
+}
+
+// size is the size a frame of the size is resized to.
+func (r *ResizeOptions) size(size image.Point) image.Point {
+	scale := r.scale(size)
+	if r.MaxDimension > 0 {
+		longest := float64(size.X)
+		if size.Y > size.X {
+			longest = float64(size.Y)
+		}
+		scale = math.Min(scale, float64(r.MaxDimension)/longest)
+	}
+	if scale == 1 {
+		return size
+	}
+	return image.Pt(
+		int(math.Max(1, math.Round(float64(size.X)*scale))),
+		int(math.Max(1, math.Round(float64(size.Y)*scale))),
+	)
+}
+
+// covers is true if a frame of the size only needs to be reduced to meet the target size. MaxDimension is treated as
+// the target length of the longest side.
+func (r *ResizeOptions) covers(size image.Point) bool {
+	if r.Width > 0 || r.Height > 0 {
+		if r.scale(size) > 1 {
+			return false
+		}
+	}
+	if r.MaxDimension > 0 && size.X < r.MaxDimension && size.Y < r.MaxDimension {
+		return false
+	}
+	return true
+}
+
+// resizeFrame resizes the frame to the target size.
+func resizeFrame(r *ResizeOptions, frame *image.NRGBA) *image.NRGBA {
+	size := r.size(frame.Bounds().Size())
+	if size == frame.Bounds().Size() {
+		return frame
+	}
+	filter := r.Filter
+	if filter.Kernel == nil && filter.Support == 0 {
+		filter = imaging.Lanczos
+	}
+	return imaging.Resize(frame, size.X, size.Y, filter)
+}
+
+// autoZoom changes the zoom level to the smallest zoom level at which the animation area is at least the resize
+// target size. The crop area is scaled to the new zoom level.
+func autoZoom(opts *LoopOptions) error {
+	maxLevel := opts.Sector.MaxZoomLevel - opts.Product.ZoomLevelAdjust
+	var size image.Point
+	if opts.Track != nil {
+		size = opts.centerSize
+	} else {
+		size = cropArea(opts.Sector, opts.zoom, opts.crop).Size()
+	}
+	level := -1
+	var scaled image.Point
+	for z := 0; z <= maxLevel && level < 0; z++ {
+		scaled = rotatedSize(scalePoint(size, math.Pow(2, float64(z-opts.zoom.Level))), opts)
+		if opts.Resize.covers(scaled) {
+			level = z
+		}
+	}
+	if level < 0 {
+		log.Warn().Msgf("The %dx%d image at the max zoom level %d is smaller than the requested size and will "+
+			"be enlarged.", scaled.X, scaled.Y, maxLevel)
+		level = maxLevel
+	}
+	if level == opts.zoom.Level {
+		return nil
+	}
+	log.Debug().Msgf("Using zoom level %d instead of %d", level, opts.zoom.Level)
+
+	scale := math.Pow(2, float64(level-opts.zoom.Level))
+	opts.zoom = opts.Satellite.ZoomLevels()[level]
+	opts.centerSize = scalePoint(opts.centerSize, scale)
+	var crop image.Rectangle
+	var err error
+	switch {
+	case opts.BoundingBox != nil:
+		crop, err = opts.projection.BoundingBoxCrop(opts.BoundingBox, opts.zoom)
+	case opts.Center != nil:
+		crop, err = opts.projection.CenterCrop(*opts.Center, opts.centerSize, opts.zoom)
+	case opts.crop != nil:
+		crop = image.Rect(
+			int(math.Floor(float64(opts.crop.Min.X)*scale)),
+			int(math.Floor(float64(opts.crop.Min.Y)*scale)),
+			int(math.Ceil(float64(opts.crop.Max.X)*scale)),
+			int(math.Ceil(float64(opts.crop.Max.Y)*scale)),
+		)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to crop at zoom level %d: %w", level, err)
+	}
+	opts.crop = &crop
+	return nil
+}
+
+func scalePoint(pt image.Point, scale float64) image.Point {
+	return image.Pt(int(math.Round(float64(pt.X)*scale)), int(math.Round(float64(pt.Y)*scale)))
+}
+
+// rotatedSize is the size of a frame of the size after it has been rotated.
+func rotatedSize(size image.Point, opts *LoopOptions) image.Point {
+	if opts.Angle == 0 || opts.FileFormat == GeoPNG {
+		return size
+	}
+	sin, cos := math.Sincos(degToRad(opts.Angle))
+	w, h := float64(size.X), float64(size.Y)
+	return image.Pt(
+		int(math.Ceil(math.Abs(w*cos)+math.Abs(h*sin))),
+		int(math.Ceil(math.Abs(w*sin)+math.Abs(h*cos))),
+	)
+}
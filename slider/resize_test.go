@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"testing"
+)
+
+func TestResizeSize(t *testing.T) {
+	src := image.Pt(1000, 600)
+	assert.Equal(t, image.Pt(500, 300), (&ResizeOptions{Width: 500}).size(src))
+	assert.Equal(t, image.Pt(500, 300), (&ResizeOptions{Height: 300}).size(src))
+	assert.Equal(t, image.Pt(500, 300), (&ResizeOptions{Width: 500, Height: 500}).size(src), "Frames should fit")
+	assert.Equal(t, image.Pt(2000, 1200), (&ResizeOptions{Width: 2000}).size(src), "Frames should be enlarged")
+	assert.Equal(t, image.Pt(800, 480), (&ResizeOptions{MaxDimension: 800}).size(src))
+	assert.Equal(t, src, (&ResizeOptions{MaxDimension: 1200}).size(src), "Frames should not be enlarged")
+	assert.Equal(t, src, (&ResizeOptions{Width: 2000, MaxDimension: 1000}).size(src))
+
+	assert.True(t, (&ResizeOptions{Width: 500}).covers(src))
+	assert.False(t, (&ResizeOptions{Width: 1200}).covers(src))
+	assert.False(t, (&ResizeOptions{MaxDimension: 1200}).covers(src))
+}
+
+func TestAutoZoom(t *testing.T) {
+	satellite := testInventory(t).Satellites["goes-16"]
+	crop := image.Rect(0, 0, 2000, 1000)
+	opts := &LoopOptions{
+		Satellite: satellite,
+		Sector:    satellite.Sectors["full-disk"],
+		Product:   satellite.Products["geocolor"],
+		Resize:    &ResizeOptions{Width: 400, AutoZoom: true},
+		crop:      &crop,
+		zoom:      satellite.ZoomLevels()[3],
+	}
+	require.NoError(t, autoZoom(opts))
+	assert.Equal(t, 1, opts.zoom.Level)
+	assert.Equal(t, image.Rect(0, 0, 500, 250), *opts.crop)
+
+	// The max zoom level is used if no zoom level is large enough
+	opts.Resize.Width = 100000
+	require.NoError(t, autoZoom(opts))
+	assert.Equal(t, 4, opts.zoom.Level, "Max zoom level should include the product adjustment")
+	assert.Equal(t, image.Rect(0, 0, 4000, 2000), *opts.crop)
+}
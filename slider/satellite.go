@@ -62,7 +62,7 @@ func (s *Satellite) ValidSector(sector *Sector) bool {
 	if s.Sectors == nil {
 		return false
 	}
-	c, ok := s.Sectors[sector.Value]
+	c, ok := s.Sectors[sector.ID()]
 	if !ok {
 		return false
 	}
@@ -77,7 +77,7 @@ func (s *Satellite) ValidProduct(product *Product) bool {
 	if s.Products == nil {
 		return false
 	}
-	p, ok := s.Products[product.Value]
+	p, ok := s.Products[product.ID()]
 	if !ok {
 		return false
 	}
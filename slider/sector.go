@@ -27,6 +27,9 @@ type Sector struct {
 	Defaults *ProductDefaults `json:"defaults"`
 	// DefaultProduct is the default product selected by SLIDER for this sector.
 	DefaultProduct string `json:"default_product"`
+	// LatLonQuery contains the geostationary projection parameters for this sector. This is nil for sectors that
+	// SLIDER doesn't support latitude/longitude lookups for.
+	LatLonQuery *LatLonQuery `json:"lat_lon_query"`
 	// MaxZoomLevel is the max zoom level or resolution that this is available for this sector.
 	MaxZoomLevel int `json:"max_zoom_level"`
 	// MissingProducts is a list of satellite products that are unavailable for this sector (typically due to the lack
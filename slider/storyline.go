@@ -0,0 +1,223 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/color"
+	"math"
+	"path"
+	"time"
+)
+
+// DefaultStorylineTitle is the title of a storyline if no title is given.
+const DefaultStorylineTitle = "Storyline"
+
+// DefaultTitleFrames is the number of frames that each title card of a storyline is shown for if no number is given.
+const DefaultTitleFrames = 10
+
+// StorylineOptions are the options used to play several loops one after another in a single animation.
+type StorylineOptions struct {
+	// Crossfade is the number of frames blended between the last frame of each segment and the first frame of the
+	// next. Segments cut straight to the next segment if Crossfade is zero.
+	Crossfade int
+	// Segments are the loops in the order they are played. The output options of the first segment, such as
+	// FileFormat, Speed, and OutputDirectory, are used for the whole storyline.
+	Segments []*StorylineSegment
+	// Size is the size in pixels of the storyline frames. The frames of each segment are scaled to fit within Size
+	// and centered on a black background. The size of the first segment's frames is used if Size is zero.
+	Size image.Point
+	// Title is the title of the storyline shown by the HTML viewer. DefaultStorylineTitle is used if Title is empty.
+	Title string
+	// TitleFrames is the number of frames that each title card is shown for. DefaultTitleFrames is used if
+	// TitleFrames is zero.
+	TitleFrames int
+}
+
+// StorylineSegment is a single loop of a storyline.
+type StorylineSegment struct {
+	// Loop contains the options of the segment's loop. Each segment is played in the direction of its LoopStyle.
+	Loop *LoopOptions
+	// Title is the text drawn on a title card shown before the segment. No title card is shown if Title is empty.
+	Title string
+}
+
+// storyline contains the frames of every segment of a storyline.
+type storyline struct {
+	frames []image.Image
+	// times is the capture time shown in each frame. Title cards use the time of the first frame of their segment.
+	times []time.Time
+	// synthetic is whether each frame was interpolated or blended between segments.
+	synthetic []bool
+	// first and last are the earliest and latest capture times of the images selected for every segment.
+	first, last time.Time
+	// size is the size of every frame.
+	size image.Point
+}
+
+// CreateStoryline renders each segment of the storyline and saves them one after another as a single animation.
+func CreateStoryline(opts *StorylineOptions) error {
+	if len(opts.Segments) == 0 {
+		return fmt.Errorf("storylines must have at least one segment")
+	}
+	output := *opts.Segments[0].Loop
+	switch output.FileFormat {
+	case GIF, PNG, WebP, Video, HTML:
+	default:
+		return fmt.Errorf("storylines can only be saved as GIF, PNG, WebP, video, or HTML animations")
+	}
+	// Segments are already in the order they are played in
+	output.Loop = ForwardLoop
+	output.Compare = nil
+	// The storyline is titled on its own instead of with the first segment's satellite, sector, and product
+	output.title = opts.Title
+	if output.title == "" {
+		output.title = DefaultStorylineTitle
+	}
+	if output.Timing != nil && output.Timing.Proportional {
+		log.Warn().Msg("Proportional frame delays don't apply to storylines and will not be used.")
+		timing := *output.Timing
+		timing.Proportional = false
+		output.Timing = &timing
+	}
+
+	story, err := renderStoryline(opts, output.FileFormat)
+	if err != nil {
+		return err
+	}
+	outPath := path.Join(output.OutputDirectory, fmt.Sprintf("cira-rammb-slider_storyline_%d-segments_%dx%d_%s-%s",
+		len(opts.Segments), story.size.X, story.size.Y, story.first.Format("20060102150405"),
+		story.last.Format("20060102150405")))
+	return saveLoop(&output, outPath, story.frames, story.times, story.synthetic)
+}
+
+// renderStoryline renders the loop of each segment in the file format and joins them together with title cards and
+// crossfades into frames of the same size.
+func renderStoryline(opts *StorylineOptions, format FileFormat) (*storyline, error) {
+	if opts.Crossfade < 0 || opts.TitleFrames < 0 {
+		return nil, fmt.Errorf("the number of crossfade and title frames can't be negative")
+	}
+	if opts.Size.X < 0 || opts.Size.Y < 0 {
+		return nil, fmt.Errorf("storyline size can't be negative: %dx%d", opts.Size.X, opts.Size.Y)
+	}
+	story := &storyline{size: opts.Size}
+	for i, segment := range opts.Segments {
+		log.Debug().Msgf("Rendering storyline segment %d of %d", i+1, len(opts.Segments))
+		loopOpts := *segment.Loop
+		loopOpts.FileFormat = format
+		loop, err := renderLoop(&loopOpts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render segment %d: %w", i+1, err)
+		}
+		err = story.add(opts, segment.Title, loop, loopOpts.Loop)
+		if err != nil {
+			return nil, fmt.Errorf("unable to add segment %d: %w", i+1, err)
+		}
+	}
+	return story, nil
+}
+
+// add appends the frames of a segment's loop played in the loop style to the storyline. The frames are preceded by
+// a title card if the title isn't empty and blended with the end of the previous segment.
+func (s *storyline) add(opts *StorylineOptions, title string, loop *renderedLoop, style LoopStyle) error {
+	if len(loop.images) == 0 {
+		return fmt.Errorf("the segment has no frames")
+	}
+	if s.size.X == 0 || s.size.Y == 0 {
+		s.size = loop.images[0].Bounds().Size()
+	}
+	if s.first.IsZero() || loop.captured[0].Before(s.first) {
+		s.first = loop.captured[0]
+	}
+	if last := loop.captured[len(loop.captured)-1]; last.After(s.last) {
+		s.last = last
+	}
+	titleFrames := opts.TitleFrames
+	if titleFrames == 0 {
+		titleFrames = DefaultTitleFrames
+	}
+
+	order, err := loopOrder(len(loop.images), style)
+	if err != nil {
+		return err
+	}
+	var frames []*image.NRGBA
+	var times []time.Time
+	if title != "" {
+		card, err := titleCard(title, s.size)
+		if err != nil {
+			return fmt.Errorf("unable to draw title card: %w", err)
+		}
+		for i := 0; i < titleFrames; i++ {
+			frames = append(frames, card)
+			times = append(times, loop.times[order[0]])
+		}
+	}
+	synthetic := make([]bool, len(frames))
+	for _, i := range order {
+		frames = append(frames, fitFrame(loop.images[i], s.size))
+		times = append(times, loop.times[i])
+		synthetic = append(synthetic, loop.synthetic[i])
+	}
+
+	if len(s.frames) > 0 {
+		previous := s.frames[len(s.frames)-1].(*image.NRGBA)
+		for i := 1; i <= opts.Crossfade; i++ {
+			s.frames = append(s.frames, crossfade(previous, frames[0], float64(i)/float64(opts.Crossfade+1)))
+			s.times = append(s.times, times[0])
+			s.synthetic = append(s.synthetic, true)
+		}
+	}
+	for _, frame := range frames {
+		s.frames = append(s.frames, frame)
+	}
+	s.times = append(s.times, times...)
+	s.synthetic = append(s.synthetic, synthetic...)
+	return nil
+}
+
+// fitFrame scales the image to fit within the size and centers it on a black background.
+func fitFrame(img image.Image, size image.Point) *image.NRGBA {
+	bounds := img.Bounds().Size()
+	scale := math.Min(float64(size.X)/float64(bounds.X), float64(size.Y)/float64(bounds.Y))
+	width := int(math.Max(1, math.Round(float64(bounds.X)*scale)))
+	height := int(math.Max(1, math.Round(float64(bounds.Y)*scale)))
+	if width != bounds.X || height != bounds.Y {
+		img = imaging.Resize(img, width, height, imaging.Lanczos)
+	}
+	return imaging.PasteCenter(imaging.New(size.X, size.Y, color.Black), img)
+}
+
+// titleCard draws the title in white in the middle of a black image of the size. The font is made smaller if the
+// title doesn't fit across the image.
+func titleCard(title string, size image.Point) (*image.NRGBA, error) {
+	fontSize := math.Max(12, math.Round(float64(size.Y)/12))
+	text, err := textImage(title, fontSize, color.White)
+	if err != nil {
+		return nil, err
+	}
+	if maxWidth := float64(size.X) * 0.9; float64(text.Bounds().Dx()) > maxWidth {
+		fontSize = math.Max(12, math.Floor(fontSize*maxWidth/float64(text.Bounds().Dx())))
+		text, err = textImage(title, fontSize, color.White)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return imaging.OverlayCenter(imaging.New(size.X, size.Y, color.Black), text, 1), nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStorylineAdd(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	fullDisk := &renderedLoop{
+		images:    []image.Image{imaging.New(200, 200, red), imaging.New(200, 200, red)},
+		times:     []time.Time{start, start.Add(10 * time.Minute)},
+		synthetic: []bool{false, false},
+		captured:  []time.Time{start, start.Add(10 * time.Minute)},
+	}
+	mesoscale := &renderedLoop{
+		images:    []image.Image{imaging.New(50, 25, blue), imaging.New(50, 25, blue), imaging.New(50, 25, blue)},
+		times:     []time.Time{start.Add(time.Hour), start.Add(61 * time.Minute), start.Add(62 * time.Minute)},
+		synthetic: []bool{false, true, false},
+		captured:  []time.Time{start.Add(time.Hour), start.Add(62 * time.Minute)},
+	}
+	opts := &StorylineOptions{Crossfade: 3, TitleFrames: 2}
+
+	story := &storyline{}
+	require.NoError(t, story.add(opts, "", fullDisk, ForwardLoop))
+	require.NoError(t, story.add(opts, "Mesoscale", mesoscale, RockLoop))
+	assert.Equal(t, image.Pt(200, 200), story.size, "The first segment should set the size")
+	assert.Equal(t, start, story.first)
+	assert.Equal(t, start.Add(62*time.Minute), story.last)
+
+	// 2 full disk frames, 3 crossfade frames, 2 title frames, and 6 rocking mesoscale frames
+	require.Len(t, story.frames, 13)
+	require.Len(t, story.times, 13)
+	require.Len(t, story.synthetic, 13)
+	for _, frame := range story.frames {
+		assert.Equal(t, image.Pt(200, 200), frame.Bounds().Size())
+	}
+	assert.Equal(t, []bool{false, false, true, true, true, false, false, false, true, false, false, true, false},
+		story.synthetic)
+	assert.Equal(t, start.Add(time.Hour), story.times[2], "Crossfades should show the time of the next frame")
+	assert.Equal(t, start.Add(time.Hour), story.times[5], "Title cards should show the time of the first frame")
+	assert.Equal(t, start.Add(62*time.Minute), story.times[9])
+	assert.Equal(t, start.Add(time.Hour), story.times[12])
+
+	// The crossfade blends from the last full disk frame into the title card
+	middle := imaging.Clone(story.frames[3]).NRGBAAt(0, 0)
+	assert.Equal(t, color.NRGBA{R: 128, A: 255}, middle)
+	assert.Equal(t, color.NRGBA{A: 255}, imaging.Clone(story.frames[5]).NRGBAAt(0, 0))
+
+	assert.Error(t, story.add(opts, "", &renderedLoop{}, ForwardLoop), "Empty segments should fail")
+}
+
+func TestFitFrame(t *testing.T) {
+	blue := color.NRGBA{B: 255, A: 255}
+	frame := fitFrame(imaging.New(100, 50, blue), image.Pt(60, 60))
+	assert.Equal(t, image.Rect(0, 0, 60, 60), frame.Bounds())
+	assert.Equal(t, color.NRGBA{A: 255}, frame.NRGBAAt(30, 5), "Frames should be letterboxed")
+	assert.Equal(t, blue, frame.NRGBAAt(30, 30))
+	assert.Equal(t, color.NRGBA{A: 255}, frame.NRGBAAt(30, 54))
+
+	// Smaller frames are enlarged to fill the size
+	frame = fitFrame(imaging.New(10, 10, blue), image.Pt(40, 20))
+	assert.Equal(t, color.NRGBA{A: 255}, frame.NRGBAAt(5, 10))
+	assert.Equal(t, blue, frame.NRGBAAt(11, 1))
+	assert.Equal(t, blue, frame.NRGBAAt(28, 18))
+}
+
+func TestTitleCard(t *testing.T) {
+	card, err := titleCard(strings.Repeat("Hurricane Ida ", 4), image.Pt(600, 300))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 600, 300), card.Bounds())
+	minX, maxX := card.Bounds().Dx(), 0
+	for y := 0; y < card.Bounds().Dy(); y++ {
+		for x := 0; x < card.Bounds().Dx(); x++ {
+			if card.NRGBAAt(x, y).R > 0 {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+		}
+	}
+	assert.Greater(t, minX, 0, "Long titles should be shrunk to fit")
+	assert.Less(t, maxX, 599)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The Go Regular font is compiled into the binary so that text can be rendered without any system fonts.
+var (
+	textFont     *sfnt.Font
+	textFontErr  error
+	textFontOnce sync.Once
+)
+
+func loadTextFont() (*sfnt.Font, error) {
+	textFontOnce.Do(func() {
+		textFont, textFontErr = sfnt.Parse(goregular.TTF)
+	})
+	return textFont, textFontErr
+}
+
+// textMask renders the text into an alpha mask. Size is the height of the font in pixels. Multiple lines of text can
+// be separated with newlines.
+func textMask(text string, size float64) (*image.Alpha, error) {
+	f, err := loadTextFont()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load font: %w", err)
+	}
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(math.Round(size * 64))
+	metrics, err := f.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get font metrics: %w", err)
+	}
+	lineHeight := math.Ceil(float64(metrics.Height) / 64)
+	ascent := float64(metrics.Ascent) / 64
+
+	lines := strings.Split(text, "\n")
+	var width float64
+	for _, line := range lines {
+		w, err := textWidth(f, &buf, line, ppem)
+		if err != nil {
+			return nil, err
+		}
+		width = math.Max(width, w)
+	}
+	w, h := int(math.Ceil(width)), int(lineHeight)*len(lines)
+	if w == 0 || h == 0 {
+		return image.NewAlpha(image.Rect(0, 0, w, h)), nil
+	}
+
+	z := vector.NewRasterizer(w, h)
+	for i, line := range lines {
+		x, y := float32(0), float32(ascent+float64(i)*lineHeight)
+		prev := sfnt.GlyphIndex(0)
+		for j, r := range line {
+			idx, err := f.GlyphIndex(&buf, r)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find glyph for '%c': %w", r, err)
+			}
+			if j > 0 {
+				kern, err := f.Kern(&buf, prev, idx, ppem, font.HintingNone)
+				if err == nil {
+					x += float32(kern) / 64
+				}
+			}
+			segments, err := f.LoadGlyph(&buf, idx, ppem, nil)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load glyph for '%c': %w", r, err)
+			}
+			for _, seg := range segments {
+				a := seg.Args
+				switch seg.Op {
+				case sfnt.SegmentOpMoveTo:
+					z.MoveTo(x+fx(a[0].X), y+fx(a[0].Y))
+				case sfnt.SegmentOpLineTo:
+					z.LineTo(x+fx(a[0].X), y+fx(a[0].Y))
+				case sfnt.SegmentOpQuadTo:
+					z.QuadTo(x+fx(a[0].X), y+fx(a[0].Y), x+fx(a[1].X), y+fx(a[1].Y))
+				case sfnt.SegmentOpCubeTo:
+					z.CubeTo(x+fx(a[0].X), y+fx(a[0].Y), x+fx(a[1].X), y+fx(a[1].Y), x+fx(a[2].X), y+fx(a[2].Y))
+				}
+			}
+			z.ClosePath()
+			advance, err := f.GlyphAdvance(&buf, idx, ppem, font.HintingNone)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get glyph advance for '%c': %w", r, err)
+			}
+			x += float32(advance) / 64
+			prev = idx
+		}
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return mask, nil
+}
+
+func textWidth(f *sfnt.Font, buf *sfnt.Buffer, line string, ppem fixed.Int26_6) (float64, error) {
+	var width fixed.Int26_6
+	prev := sfnt.GlyphIndex(0)
+	for j, r := range line {
+		idx, err := f.GlyphIndex(buf, r)
+		if err != nil {
+			return 0, fmt.Errorf("unable to find glyph for '%c': %w", r, err)
+		}
+		if j > 0 {
+			kern, err := f.Kern(buf, prev, idx, ppem, font.HintingNone)
+			if err == nil {
+				width += kern
+			}
+		}
+		advance, err := f.GlyphAdvance(buf, idx, ppem, font.HintingNone)
+		if err != nil {
+			return 0, fmt.Errorf("unable to get glyph advance for '%c': %w", r, err)
+		}
+		width += advance
+		prev = idx
+	}
+	return float64(width) / 64, nil
+}
+
+func fx(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
+
+// textImage renders the text into a new image in the color that is transparent everywhere except for the text.
+func textImage(text string, size float64, col color.Color) (*image.NRGBA, error) {
+	mask, err := textMask(text, size)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(mask.Bounds())
+	draw.DrawMask(img, img.Bounds(), image.NewUniform(col), image.Point{}, mask, image.Point{}, draw.Src)
+	return img, nil
+}
+
+// drawTextBox draws the text at the point with an optional background box behind it. The point is the upper-left
+// hand corner of the box. Padding is the space between the edge of the box and the text.
+func drawTextBox(dst draw.Image, mask *image.Alpha, pt image.Point, padding int, fg, bg color.Color) {
+	box := image.Rectangle{Min: pt, Max: pt.Add(mask.Bounds().Size()).Add(image.Pt(2*padding, 2*padding))}
+	if bg != nil {
+		draw.Draw(dst, box, image.NewUniform(bg), image.Point{}, draw.Over)
+	}
+	textArea := mask.Bounds().Add(pt.Add(image.Pt(padding, padding)))
+	draw.DrawMask(dst, textArea, image.NewUniform(fg), image.Point{}, mask, mask.Bounds().Min, draw.Over)
+}
+
+// ParseHexColor parses a color in the format #RRGGBB or #RRGGBBAA. The leading # is optional.
+func ParseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("color must use the format #RRGGBB or #RRGGBBAA: '%s'", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid hex color '%s'", s)
+	}
+	if len(hex) == 6 {
+		v = v<<8 | 0xff
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image/color"
+	"testing"
+)
+
+func TestParseHexColor(t *testing.T) {
+	c, err := ParseHexColor("#ff8000")
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 255, G: 128, B: 0, A: 255}, c)
+	c, err = ParseHexColor("00000099")
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{A: 0x99}, c)
+	_, err = ParseHexColor("#fff")
+	assert.Error(t, err)
+	_, err = ParseHexColor("#gggggg")
+	assert.Error(t, err)
+}
+
+func TestTextMask(t *testing.T) {
+	one, err := textMask("2021-08-29 12:00 UTC", 20)
+	require.NoError(t, err)
+	two, err := textMask("2021-08-29 12:00 UTC\nHurricane Ida", 20)
+	require.NoError(t, err)
+	assert.Greater(t, one.Bounds().Dx(), 100)
+	assert.Equal(t, one.Bounds().Dx(), two.Bounds().Dx())
+	assert.Equal(t, 2*one.Bounds().Dy(), two.Bounds().Dy())
+
+	var covered int
+	for _, a := range one.Pix {
+		if a > 0 {
+			covered++
+		}
+	}
+	assert.Greater(t, covered, 0, "Text should be drawn into the mask")
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// maxProportionalDelay is the largest multiple of the normal delay that a proportional delay can be so that long
+	// gaps in the data don't stall the animation.
+	maxProportionalDelay = 10
+	// minProportionalDelay is the smallest proportional delay in hundredths of a second. Many viewers display GIF
+	// frames with shorter delays for much longer.
+	minProportionalDelay = 2
+)
+
+// TimingOptions are the options used to vary the time that each frame of an animation is displayed.
+type TimingOptions struct {
+	// FirstDelay is the time to display the first frame in hundredths of a second. The normal delay is used if
+	// FirstDelay is zero.
+	FirstDelay int
+	// LastDelay is the time to display the last frame in hundredths of a second so that the animation pauses before
+	// it starts over. The normal delay is used if LastDelay is zero.
+	LastDelay int
+	// LoopCount is the number of times the animation is played. The animation is played forever if LoopCount is
+	// zero.
+	LoopCount int
+	// Proportional makes the delay of each frame proportional to the time between its capture time and the capture
+	// time of the next frame. Frames separated by the median time step are displayed for the normal delay.
+	Proportional bool
+}
+
+// loopOrder returns the index of the image displayed in each frame of an animation of n images.
+func loopOrder(n int, style LoopStyle) ([]int, error) {
+	var order []int
+	switch style {
+	case ForwardLoop, RockLoop:
+		for i := 0; i < n; i++ {
+			order = append(order, i)
+		}
+		if style == RockLoop {
+			for i := n - 1; i >= 0; i-- {
+				order = append(order, i)
+			}
+		}
+	case ReverseLoop:
+		for i := n - 1; i >= 0; i-- {
+			order = append(order, i)
+		}
+	default:
+		return nil, fmt.Errorf("unknown animation loop style: %v", style)
+	}
+	return order, nil
+}
+
+// frameDelays returns the delay of each frame of an animation in hundredths of a second in the order the frames are
+// displayed. The times are the capture times of the images in chronological order.
+func frameDelays(times []time.Time, delay int, style LoopStyle, timing *TimingOptions) ([]int, error) {
+	if timing.FirstDelay < 0 || timing.LastDelay < 0 {
+		return nil, fmt.Errorf("frame delays can't be negative")
+	}
+	if timing.LoopCount < 0 {
+		return nil, fmt.Errorf("loop count can't be negative: %d", timing.LoopCount)
+	}
+	order, err := loopOrder(len(times), style)
+	if err != nil {
+		return nil, err
+	}
+	delays := make([]int, len(order))
+	for i := range delays {
+		delays[i] = delay
+	}
+	if len(delays) == 0 {
+		return delays, nil
+	}
+	if timing.Proportional {
+		gaps := make([]time.Duration, len(order)-1)
+		var steps []time.Duration
+		for i := range gaps {
+			gaps[i] = times[order[i+1]].Sub(times[order[i]])
+			if gaps[i] < 0 {
+				gaps[i] = -gaps[i]
+			}
+			if gaps[i] > 0 {
+				steps = append(steps, gaps[i])
+			}
+		}
+		if len(steps) > 0 {
+			sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+			median := float64(steps[len(steps)/2])
+			minDelay := minProportionalDelay
+			if delay < minDelay {
+				minDelay = delay
+			}
+			for i, gap := range gaps {
+				d := int(math.Round(float64(delay) * float64(gap) / median))
+				if d < minDelay {
+					d = minDelay
+				}
+				if d > delay*maxProportionalDelay {
+					d = delay * maxProportionalDelay
+				}
+				delays[i] = d
+			}
+		}
+	}
+	if timing.FirstDelay > 0 {
+		delays[0] = timing.FirstDelay
+	}
+	if timing.LastDelay > 0 {
+		delays[len(delays)-1] = timing.LastDelay
+	}
+	return delays, nil
+}
+
+// loopDelays returns the delay of each frame of the loop in display order, or nil if every frame uses the normal
+// delay. The times are the capture times of the images being animated.
+func loopDelays(opts *LoopOptions, times []time.Time) ([]int, error) {
+	if opts.Timing == nil {
+		return nil, nil
+	}
+	timing := opts.Timing
+	if timing.Proportional && opts.Compare != nil && opts.Compare.overlay() {
+		// Swipe and flicker comparisons show each capture time in several frames
+		t := *timing
+		t.Proportional = false
+		timing = &t
+	}
+	return frameDelays(times, opts.Speed, opts.Loop, timing)
+}
+
+// keptTimes returns the times at the indexes that were kept by the file size budget.
+func keptTimes(times []time.Time, kept []int) []time.Time {
+	kt := make([]time.Time, len(kept))
+	for i, j := range kept {
+		kt[i] = times[j]
+	}
+	return kt
+}
+
+// gifLoopCount returns the GIF loop count for the number of times the animation is played. GIFs are played one
+// more time than their loop count, and a loop count of -1 plays them once.
+func gifLoopCount(plays int) int {
+	switch plays {
+	case 0:
+		return 0
+	case 1:
+		return -1
+	default:
+		return plays - 1
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+func TestFrameDelays(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for _, minutes := range []int{0, 10, 20, 60, 70} {
+		times = append(times, start.Add(time.Duration(minutes)*time.Minute))
+	}
+
+	delays, err := frameDelays(times, 10, ForwardLoop, &TimingOptions{LastDelay: 150})
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 10, 10, 150}, delays)
+
+	delays, err = frameDelays(times, 10, ForwardLoop, &TimingOptions{Proportional: true})
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 40, 10, 10}, delays, "The gap should be displayed for longer")
+
+	delays, err = frameDelays(times, 10, ReverseLoop, &TimingOptions{Proportional: true, FirstDelay: 50})
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 40, 10, 10, 10}, delays)
+
+	delays, err = frameDelays(times, 10, RockLoop, &TimingOptions{Proportional: true})
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 40, 10, 2, 10, 40, 10, 10, 10}, delays, "Repeated frames should be short")
+
+	delays, err = frameDelays(append(times, start.Add(24*time.Hour)), 10, ForwardLoop,
+		&TimingOptions{Proportional: true})
+	require.NoError(t, err)
+	assert.Equal(t, 100, delays[4], "Long gaps should be limited")
+
+	_, err = frameDelays(times, 10, ForwardLoop, &TimingOptions{LoopCount: -1})
+	assert.Error(t, err)
+}
+
+func TestAnimateTiming(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	var images []image.Image
+	var times []time.Time
+	for i, c := range []uint8{0, 0, 100} {
+		img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+		draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		img.Set(5, 5, color.RGBA{R: c, A: 255})
+		images = append(images, img)
+		times = append(times, start.Add(time.Duration(i)*10*time.Minute))
+	}
+	opts := &LoopOptions{
+		GIF:    &GIFOptions{Optimize: true},
+		Speed:  10,
+		Timing: &TimingOptions{LastDelay: 100, LoopCount: 1},
+	}
+
+	animation, _, err := animateGIF(opts, images, times)
+	require.NoError(t, err)
+	assert.Equal(t, []int{20, 100}, animation.Delay, "Delays should be merged when unchanged frames are removed")
+	assert.Equal(t, -1, animation.LoopCount, "The animation should play once")
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, animation))
+
+	png, _, err := animatePNG(opts, images, times)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(100), png.Frames[2].DelayNumerator)
+	assert.Equal(t, uint(1), png.LoopCount)
+
+	webp, _, err := animateWebP(opts, images, times)
+	require.NoError(t, err)
+	assert.Equal(t, 100, webp.Frames[2].Delay)
+	buf.Reset()
+	require.NoError(t, EncodeWebP(&buf, webp))
+	chunks := webpChunks(t, buf.Bytes()[12:])
+	assert.Equal(t, []byte{0, 0, 0, 0, 1, 0}, chunks[1].data, "The animation should play once")
+}
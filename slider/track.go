@@ -0,0 +1,200 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trackTimeFormats are the timestamp formats accepted in track files. Timestamps without a time zone are UTC.
+var trackTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"20060102150405",
+	"200601021504",
+	"2006010215",
+}
+
+// TrackPoint is a single position along a storm track.
+type TrackPoint struct {
+	Time time.Time
+	Lat  float64
+	Lon  float64
+}
+
+// Track is the path of a moving feature such as a tropical cyclone. Points are sorted in chronological order.
+type Track struct {
+	Points []TrackPoint
+}
+
+// LoadTrack reads a track file. Files ending in .json are parsed with ParseTrackJSON and all other files are
+// parsed with ParseTrackCSV.
+func LoadTrack(filePath string) (*Track, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read track file: %w", err)
+	}
+	if strings.EqualFold(path.Ext(filePath), ".json") {
+		return ParseTrackJSON(data)
+	}
+	return ParseTrackCSV(data)
+}
+
+// ParseTrackCSV parses a track from CSV data with the columns time, lat, and lon. A header row is optional.
+func ParseTrackCSV(data []byte) (*Track, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+	track := new(Track)
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read track CSV: %w", err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("track CSV line %d must have time, lat, and lon columns", line)
+		}
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if line == 1 && (latErr != nil || lonErr != nil) {
+			// Header row
+			continue
+		}
+		if latErr != nil || lonErr != nil {
+			return nil, fmt.Errorf("unable to parse position on track CSV line %d: %s, %s", line, record[1],
+				record[2])
+		}
+		t, err := parseTrackTime(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("track CSV line %d: %w", line, err)
+		}
+		track.Points = append(track.Points, TrackPoint{Time: t, Lat: lat, Lon: lon})
+	}
+	return track, track.sort()
+}
+
+// ParseTrackJSON parses a track from a JSON list of objects with "time", "lat", and "lon" keys.
+func ParseTrackJSON(data []byte) (*Track, error) {
+	var points []struct {
+		Time string  `json:"time"`
+		Lat  float64 `json:"lat"`
+		Lon  float64 `json:"lon"`
+	}
+	err := json.Unmarshal(data, &points)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode track JSON: %w", err)
+	}
+	track := new(Track)
+	for i, p := range points {
+		t, err := parseTrackTime(p.Time)
+		if err != nil {
+			return nil, fmt.Errorf("track JSON point %d: %w", i, err)
+		}
+		track.Points = append(track.Points, TrackPoint{Time: t, Lat: p.Lat, Lon: p.Lon})
+	}
+	return track, track.sort()
+}
+
+func parseTrackTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, format := range trackTimeFormats {
+		t, err := time.Parse(format, value)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse track time '%s'", value)
+}
+
+func (t *Track) sort() error {
+	if len(t.Points) == 0 {
+		return fmt.Errorf("track does not contain any points")
+	}
+	for _, p := range t.Points {
+		if p.Lat < -90 || p.Lat > 90 {
+			return fmt.Errorf("track latitude must be between -90 and 90: %v", p.Lat)
+		}
+	}
+	sort.SliceStable(t.Points, func(i, j int) bool { return t.Points[i].Time.Before(t.Points[j].Time) })
+	return nil
+}
+
+// Covers returns true if the time is within the first and last points of the track.
+func (t *Track) Covers(at time.Time) bool {
+	return !at.Before(t.Points[0].Time) && !at.After(t.Points[len(t.Points)-1].Time)
+}
+
+// Position returns the position along the track at the specified time by linearly interpolating between the
+// nearest track points. Times before or after the track return the first or last point respectively.
+func (t *Track) Position(at time.Time) LatLon {
+	first, last := t.Points[0], t.Points[len(t.Points)-1]
+	if !at.After(first.Time) {
+		return LatLon{Lat: first.Lat, Lon: normalizeLon(first.Lon)}
+	}
+	if !at.Before(last.Time) {
+		return LatLon{Lat: last.Lat, Lon: normalizeLon(last.Lon)}
+	}
+	i := sort.Search(len(t.Points), func(i int) bool { return t.Points[i].Time.After(at) })
+	a, b := t.Points[i-1], t.Points[i]
+	f := float64(at.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+	// Interpolate across the antimeridian along the shortest path
+	dLon := normalizeLon(b.Lon - a.Lon)
+	return LatLon{
+		Lat: a.Lat + f*(b.Lat-a.Lat),
+		Lon: normalizeLon(a.Lon + f*dLon),
+	}
+}
+
+// trackCrop returns the crop area of CenterSize centered on the track position at the image capture time. The crop
+// area is moved back inside the sector image when the track nears the edge so that every frame is the same size.
+func trackCrop(opts *LoopOptions, timestamp time.Time) (*image.Rectangle, error) {
+	center := opts.Track.Position(timestamp)
+	crop, err := opts.projection.centerRect(center, opts.centerSize, opts.zoom)
+	if err != nil {
+		return nil, fmt.Errorf("unable to crop to track at %v: %w", timestamp, err)
+	}
+	bounds := image.Rect(0, 0, opts.Sector.XSize(opts.zoom), opts.Sector.YSize(opts.zoom))
+	if crop.Min.X < bounds.Min.X {
+		crop = crop.Add(image.Pt(bounds.Min.X-crop.Min.X, 0))
+	}
+	if crop.Min.Y < bounds.Min.Y {
+		crop = crop.Add(image.Pt(0, bounds.Min.Y-crop.Min.Y))
+	}
+	if crop.Max.X > bounds.Max.X {
+		crop = crop.Sub(image.Pt(crop.Max.X-bounds.Max.X, 0))
+	}
+	if crop.Max.Y > bounds.Max.Y {
+		crop = crop.Sub(image.Pt(0, crop.Max.Y-bounds.Max.Y))
+	}
+	return &crop, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestParseTrackCSV(t *testing.T) {
+	data := []byte("time,lat,lon\n2021082906,29.1,-90.0\n2021082900,27.6,-89.0\n2021-08-29T12:00:00Z,30.2,-90.6\n")
+	track, err := ParseTrackCSV(data)
+	require.NoError(t, err)
+	require.Len(t, track.Points, 3)
+	assert.Equal(t, time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC), track.Points[0].Time, "Points should be sorted")
+	assert.Equal(t, 30.2, track.Points[2].Lat)
+
+	_, err = ParseTrackCSV([]byte("time,lat,lon\n"))
+	assert.Error(t, err, "Empty track should fail")
+	_, err = ParseTrackCSV([]byte("2021082900,27.6,-89.0\nyesterday,27.6,-89.0\n"))
+	assert.Error(t, err, "Invalid time should fail")
+}
+
+func TestParseTrackJSON(t *testing.T) {
+	data := []byte(`[{"time": "2021-08-29 00:00", "lat": 27.6, "lon": -89.0}, {"time": "20210829060000", "lat": 29.1, "lon": -90.0}]`)
+	track, err := ParseTrackJSON(data)
+	require.NoError(t, err)
+	require.Len(t, track.Points, 2)
+	assert.Equal(t, time.Date(2021, 8, 29, 6, 0, 0, 0, time.UTC), track.Points[1].Time)
+}
+
+func TestTrackPosition(t *testing.T) {
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	track := &Track{Points: []TrackPoint{
+		{Time: start, Lat: 20, Lon: 179},
+		{Time: start.Add(6 * time.Hour), Lat: 26, Lon: -177},
+	}}
+
+	pos := track.Position(start.Add(3 * time.Hour))
+	assert.InDelta(t, 23, pos.Lat, 1e-9)
+	assert.InDelta(t, -179, pos.Lon, 1e-9, "Position should cross the antimeridian")
+
+	assert.Equal(t, LatLon{Lat: 20, Lon: 179}, track.Position(start.Add(-time.Hour)))
+	assert.Equal(t, LatLon{Lat: 26, Lon: -177}, track.Position(start.Add(7*time.Hour)))
+	assert.True(t, track.Covers(start.Add(time.Hour)))
+	assert.False(t, track.Covers(start.Add(7*time.Hour)))
+}
+
+func TestTrackCrop(t *testing.T) {
+	sector := testInventory(t).Satellites["goes-16"].Sectors["full-disk"]
+	p, err := sector.Projection()
+	require.NoError(t, err)
+	start := time.Date(2021, 8, 29, 0, 0, 0, 0, time.UTC)
+	opts := &LoopOptions{
+		Sector:     sector,
+		CenterSize: image.Pt(300, 200),
+		Track:      &Track{Points: []TrackPoint{{Time: start, Lat: 0, Lon: -75}, {Time: start.Add(time.Hour), Lat: 0, Lon: -5}}},
+		centerSize: image.Pt(300, 200),
+		projection: p,
+		zoom:       &Zoom{Level: 1},
+	}
+
+	crop, err := trackCrop(opts, start)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(528, 578, 828, 778), *crop)
+
+	// The crop area is kept inside of the image near the limb
+	crop, err = trackCrop(opts, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1356, crop.Max.X)
+	assert.Equal(t, image.Pt(300, 200), crop.Size())
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultVideoEncoder is the name of the ffmpeg executable used to encode videos if no encoder is given. It is
+// searched for in the directories named by the PATH environment variable.
+const DefaultVideoEncoder = "ffmpeg"
+
+// aviJPEGQuality is the JPEG quality of the frames in Motion-JPEG AVI videos.
+const aviJPEGQuality = 90
+
+// videoContainer is the default codec and constant rate factor used to encode a video file type.
+type videoContainer struct {
+	codec string
+	crf   int
+}
+
+// videoContainers are the video file types that can be created with an external encoder.
+var videoContainers = map[string]videoContainer{
+	"mp4":  {codec: "libx264", crf: 23},
+	"webm": {codec: "libvpx-vp9", crf: 31},
+}
+
+// VideoOptions are the options used to encode videos.
+type VideoOptions struct {
+	// Codec is the ffmpeg name of the video codec. The default codec of the container is used if Codec is empty,
+	// which is "libx264" for MP4 and "libvpx-vp9" for WebM.
+	Codec string
+	// Container is the video file type, either "mp4" or "webm". MP4 is used if Container is empty.
+	Container string
+	// CRF is the constant rate factor of the encoder. Lower values create higher quality and larger files. The
+	// default of the container is used if CRF is zero, which is 23 for MP4 and 31 for WebM.
+	CRF int
+	// Encoder is the path to the ffmpeg executable. DefaultVideoEncoder is used if Encoder is empty. A Motion-JPEG
+	// AVI video is created instead if the encoder can't be found.
+	Encoder string
+}
+
+// VideoAnimation is a video of the loop. Frames are encoded when the video is saved.
+type VideoAnimation struct {
+	// Delay is the time to display each frame in hundredths of a second.
+	Delay   int
+	Frames  []image.Image
+	Options VideoOptions
+}
+
+// AnimateVideo creates a video of the supplied images.
+func AnimateVideo(images []image.Image, delay int, style LoopStyle, opts *VideoOptions) (*VideoAnimation, error) {
+	video := &VideoAnimation{Delay: delay, Options: *opts}
+	if video.Options.Container == "" {
+		video.Options.Container = "mp4"
+	}
+	container, ok := videoContainers[video.Options.Container]
+	if !ok {
+		return nil, fmt.Errorf("unknown video container: %s", video.Options.Container)
+	}
+	if video.Options.Codec == "" {
+		video.Options.Codec = container.codec
+	}
+	if video.Options.CRF == 0 {
+		video.Options.CRF = container.crf
+	}
+	if video.Options.Encoder == "" {
+		video.Options.Encoder = DefaultVideoEncoder
+	}
+	if delay < 1 {
+		return nil, fmt.Errorf("frame delay must be at least 1: %d", delay)
+	}
+	switch style {
+	case RockLoop:
+		video.Frames = make([]image.Image, len(images)*2)
+	case ForwardLoop, ReverseLoop:
+		video.Frames = make([]image.Image, len(images))
+	default:
+		return nil, fmt.Errorf("unknown animation loop style: %v", style)
+	}
+	for i, img := range images {
+		switch style {
+		case ForwardLoop:
+			video.Frames[i] = img
+		case ReverseLoop:
+			video.Frames[len(images)-1-i] = img
+		case RockLoop:
+			video.Frames[i] = img
+			video.Frames[(len(images)*2)-1-i] = img
+		}
+	}
+	return video, nil
+}
+
+// Size returns the size of the video, which is large enough to fit every frame.
+func (v *VideoAnimation) Size() image.Point {
+	var size image.Point
+	for _, frame := range v.Frames {
+		if s := frame.Bounds().Size(); s.X > size.X {
+			size.X = s.X
+		}
+		if s := frame.Bounds().Size(); s.Y > size.Y {
+			size.Y = s.Y
+		}
+	}
+	return size
+}
+
+// frame returns the frame drawn over a black background in the upper-left-hand corner of the video.
+func (v *VideoAnimation) frame(i int, size image.Point) *image.RGBA {
+	img := v.Frames[i]
+	frame := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(frame, frame.Bounds(), image.Black, image.Point{}, draw.Src)
+	draw.Draw(frame, img.Bounds().Sub(img.Bounds().Min), img, img.Bounds().Min, draw.Over)
+	return frame
+}
+
+// encoderArgs returns the arguments for ffmpeg to read raw RGBA frames from stdin and write the video to the output
+// file. Videos are padded to an even width and height since most players require it.
+func (v *VideoAnimation) encoderArgs(output string) []string {
+	size := v.Size()
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "rawvideo", "-pix_fmt", "rgba", "-video_size", fmt.Sprintf("%dx%d", size.X, size.Y),
+		"-framerate", "100/" + strconv.Itoa(v.Delay), "-i", "pipe:0",
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-c:v", v.Options.Codec, "-crf", strconv.Itoa(v.Options.CRF), "-pix_fmt", "yuv420p",
+	}
+	switch v.Options.Container {
+	case "mp4":
+		// Moving the index to the start of the file lets the video play before it is fully downloaded
+		args = append(args, "-movflags", "+faststart")
+	case "webm":
+		// VP8 and VP9 only use the constant rate factor if the bitrate is zero
+		args = append(args, "-b:v", "0")
+	}
+	return append(args, output)
+}
+
+// SaveVideo encodes the video into a file with the external encoder. The extension of the container will be added
+// automatically. If the encoder can't be found a Motion-JPEG .avi file is created instead. If a file with the same
+// name exists an incrementing number will be appended to the end of the file name.
+func SaveVideo(output string, video *VideoAnimation) (string, error) {
+	if len(video.Frames) == 0 {
+		return "", fmt.Errorf("no frames to encode")
+	}
+	encoder, err := exec.LookPath(video.Options.Encoder)
+	if err != nil {
+		log.Warn().Msgf("Unable to find video encoder '%s' -- saving a Motion-JPEG AVI instead.",
+			video.Options.Encoder)
+		return SaveAVI(output, video)
+	}
+	suffix := "." + video.Options.Container
+	output, err = checkFileDuplicate(output, suffix)
+	if err != nil {
+		return "", err
+	}
+	log.Debug().Msgf("Encoding %d video frames with '%s'", len(video.Frames), encoder)
+	timeIn := time.Now()
+	// The encoder is chosen by the user running slider-cli and was found with exec.LookPath above
+	cmd := exec.Command(encoder, video.encoderArgs(output+suffix)...) // #nosec G204
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", fmt.Errorf("unable to open video encoder input: %w", err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		return "", fmt.Errorf("unable to start video encoder: %w", err)
+	}
+	size := video.Size()
+	for i := range video.Frames {
+		_, err = stdin.Write(video.frame(i, size).Pix)
+		if err != nil {
+			// The encoder's error message is more useful than the broken pipe
+			break
+		}
+	}
+	_ = stdin.Close()
+	err = cmd.Wait()
+	if err != nil {
+		return "", fmt.Errorf("unable to encode video: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	timeOut := time.Now()
+	log.Debug().Msgf("Encoding took %.3fs", timeOut.Sub(timeIn).Seconds())
+	log.Debug().Msgf("Saved video to '%s'", output+suffix)
+	return output + suffix, nil
+}
+
+// EncodeAVI writes the video to w as a Motion-JPEG AVI.
+func EncodeAVI(w io.Writer, video *VideoAnimation) error {
+	if len(video.Frames) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+	size := video.Size()
+	movi := []byte("movi")
+	var index []byte
+	var maxFrame int
+	for i := range video.Frames {
+		var buf bytes.Buffer
+		err := jpeg.Encode(&buf, video.frame(i, size), &jpeg.Options{Quality: aviJPEGQuality})
+		if err != nil {
+			return fmt.Errorf("unable to encode frame %d: %w", i, err)
+		}
+		if buf.Len() > maxFrame {
+			maxFrame = buf.Len()
+		}
+		// Index offsets are relative to the start of the movi list type
+		index = append(index, "00dc"...)
+		index = appendUint32(index, 0x10) // Key frame
+		index = appendUint32(index, len(movi))
+		index = appendUint32(index, buf.Len())
+		movi = appendChunk(movi, "00dc", buf.Bytes())
+	}
+
+	var avih []byte
+	for _, v := range []int{
+		video.Delay * 10000,          // Microseconds per frame
+		maxFrame * 100 / video.Delay, // Maximum bytes per second
+		0,                            // Padding granularity
+		0x10,                         // Has index
+		len(video.Frames),            // Total frames
+		0,                            // Initial frames
+		1,                            // Streams
+		maxFrame,                     // Suggested buffer size
+		size.X, size.Y, 0, 0, 0, 0,
+	} {
+		avih = appendUint32(avih, v)
+	}
+	strh := []byte("vidsMJPG")
+	for _, v := range []int{0, 0, 0, video.Delay, 100, 0, len(video.Frames), maxFrame, -1, 0} {
+		strh = appendUint32(strh, v)
+	}
+	strh = appendUint16(appendUint16(appendUint16(appendUint16(strh, 0), 0), size.X), size.Y)
+	strf := appendUint32(nil, 40)
+	strf = appendUint32(appendUint32(strf, size.X), size.Y)
+	strf = appendUint16(appendUint16(strf, 1), 24) // Planes and bits per pixel
+	strf = append(strf, "MJPG"...)
+	for _, v := range []int{size.X * size.Y * 3, 0, 0, 0, 0} {
+		strf = appendUint32(strf, v)
+	}
+
+	strl := appendChunk(appendChunk([]byte("strl"), "strh", strh), "strf", strf)
+	hdrl := appendChunk(appendChunk([]byte("hdrl"), "avih", avih), "LIST", strl)
+	data := appendChunk([]byte("AVI "), "LIST", hdrl)
+	data = appendChunk(data, "LIST", movi)
+	data = appendChunk(data, "idx1", index)
+	header := append([]byte("RIFF"), 0, 0, 0, 0)
+	putUint32(header[4:], len(data))
+	_, err := w.Write(append(header, data...))
+	if err != nil {
+		return fmt.Errorf("unable to write AVI: %w", err)
+	}
+	return nil
+}
+
+// SaveAVI encodes the video into a Motion-JPEG .avi file. The .avi extension will be added automatically. If a file
+// with the same name exists an incrementing number will be appended to the end of the file name.
+func SaveAVI(output string, video *VideoAnimation) (string, error) {
+	output, err := checkFileDuplicate(output, ".avi")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(output+".avi", os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to open AVI file: %w", err)
+	}
+	err = EncodeAVI(f, video)
+	if err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("unable to encode AVI: %w", err)
+	}
+	log.Debug().Msgf("Saved AVI to '%s'", output+".avi")
+	err = f.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to close AVI file: %w", err)
+	}
+	return output + ".avi", nil
+}
+
+func appendUint16(buf []byte, v int) []byte {
+	return append(buf, byte(v), byte(v>>8))
+}
+
+func appendUint32(buf []byte, v int) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
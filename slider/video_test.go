@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testVideoFrames() []image.Image {
+	var images []image.Image
+	for _, c := range []color.RGBA{{R: 200, A: 255}, {B: 200, A: 255}} {
+		img := image.NewRGBA(image.Rect(0, 0, 31, 21))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		images = append(images, img)
+	}
+	return images
+}
+
+func TestEncodeAVI(t *testing.T) {
+	video, err := AnimateVideo(testVideoFrames(), 20, RockLoop, &VideoOptions{})
+	require.NoError(t, err)
+	require.Len(t, video.Frames, 4)
+	var buf bytes.Buffer
+	require.NoError(t, EncodeAVI(&buf, video))
+
+	data := buf.Bytes()
+	require.Equal(t, "RIFF", string(data[:4]))
+	require.Equal(t, "AVI ", string(data[8:12]))
+	chunks := webpChunks(t, data[12:])
+	require.Len(t, chunks, 3)
+	hdrl, movi, idx1 := chunks[0].data, chunks[1].data, chunks[2].data
+	require.Equal(t, "hdrl", string(hdrl[:4]))
+	avih := webpChunks(t, hdrl[4:])[0].data
+	assert.Equal(t, uint32(200000), binary.LittleEndian.Uint32(avih[0:]), "Frames should last 0.2 seconds")
+	assert.Equal(t, uint32(4), binary.LittleEndian.Uint32(avih[16:]))
+	assert.Equal(t, uint32(31), binary.LittleEndian.Uint32(avih[32:]))
+	assert.Equal(t, uint32(21), binary.LittleEndian.Uint32(avih[36:]))
+
+	require.Equal(t, "movi", string(movi[:4]))
+	frames := webpChunks(t, movi[4:])
+	require.Len(t, frames, 4)
+	require.Len(t, idx1, 4*16)
+	for i, frame := range frames {
+		entry := idx1[i*16:]
+		offset := binary.LittleEndian.Uint32(entry[8:])
+		assert.Equal(t, "00dc", string(movi[offset:offset+4]), "Index should point to frame %d", i)
+		assert.Equal(t, uint32(len(frame.data)), binary.LittleEndian.Uint32(entry[12:]))
+
+		img, err := jpeg.Decode(bytes.NewReader(frame.data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 31, 21), img.Bounds())
+		r, _, b, _ := img.At(15, 10).RGBA()
+		assert.Equal(t, i == 0 || i == 3, r>>8 > b>>8, "Frame %d is in the wrong order", i)
+	}
+}
+
+func TestSaveVideo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake encoder is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "slider-video")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The fake encoder saves its arguments and the raw frames it was sent
+	encoder := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nfor last; do :; done\necho \"$@\" > \"$last.args\"\ncat > \"$last\"\n"
+	require.NoError(t, ioutil.WriteFile(encoder, []byte(script), 0700))
+	video, err := AnimateVideo(testVideoFrames(), 20, ForwardLoop, &VideoOptions{Container: "webm", Encoder: encoder})
+	require.NoError(t, err)
+	output, err := SaveVideo(filepath.Join(dir, "loop"), video)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "loop.webm"), output)
+	raw, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	assert.Len(t, raw, 2*31*21*4)
+	assert.Equal(t, []byte{200, 0, 0, 255}, raw[:4])
+	args, err := ioutil.ReadFile(output + ".args")
+	require.NoError(t, err)
+	for _, arg := range []string{"-video_size 31x21", "-framerate 100/20", "-c:v libvpx-vp9", "-crf 31", "-b:v 0"} {
+		assert.Contains(t, string(args), arg)
+	}
+
+	// A Motion-JPEG AVI is saved if the encoder is missing
+	video.Options.Encoder = filepath.Join(dir, "missing")
+	output, err = SaveVideo(filepath.Join(dir, "loop"), video)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(output, "loop.avi"))
+
+	_, err = AnimateVideo(testVideoFrames(), 20, ForwardLoop, &VideoOptions{Container: "mkv"})
+	assert.Error(t, err, "Unknown container should fail")
+}
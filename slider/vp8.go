@@ -0,0 +1,1096 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+)
+
+// This file implements a lossy VP8 key frame encoder as described in RFC 6386. Each macroblock is predicted as a
+// whole with one of the 16x16 luma and 8x8 chroma prediction modes and the residuals are coded with the default
+// token probabilities. The loop filter is not used.
+
+// The prediction modes are numbered in the order used by the mode probabilities.
+const (
+	vp8PredDC = iota
+	vp8PredTM
+	vp8PredVE
+	vp8PredHE
+)
+
+// The token probability planes are specified in section 13.3.
+const (
+	vp8PlaneY1WithY2 = iota
+	vp8PlaneY2
+	vp8PlaneUV
+	vp8PlaneY1SansY2
+)
+
+const vp8MaxLevel = 2047
+
+// vp8BoolEncoder is the boolean entropy encoder used to write VP8 partitions.
+type vp8BoolEncoder struct {
+	buf []byte
+	// rangeM1 is the range minus one.
+	rangeM1 int32
+	value   int32
+	// run is the number of pending 0xff bytes that a carry may still change.
+	run   int
+	nBits int
+}
+
+func newVP8BoolEncoder() *vp8BoolEncoder {
+	return &vp8BoolEncoder{rangeM1: 254, nBits: -8}
+}
+
+func (e *vp8BoolEncoder) putBit(bit bool, prob uint8) {
+	split := (e.rangeM1 * int32(prob)) >> 8
+	if bit {
+		e.value += split + 1
+		e.rangeM1 -= split + 1
+	} else {
+		e.rangeM1 = split
+	}
+	if e.rangeM1 < 127 {
+		shift := 8 - bits.Len32(uint32(e.rangeM1+1))
+		e.rangeM1 = (e.rangeM1+1)<<uint(shift) - 1
+		e.value <<= uint(shift)
+		e.nBits += shift
+		if e.nBits > 0 {
+			e.flush()
+		}
+	}
+}
+
+// putLiteral writes the n-bit value most significant bit first with even probabilities.
+func (e *vp8BoolEncoder) putLiteral(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(v>>uint(n)&1 == 1, 128)
+	}
+}
+
+func (e *vp8BoolEncoder) flush() {
+	s := uint(8 + e.nBits)
+	b := e.value >> s
+	e.value -= b << s
+	e.nBits -= 8
+	if b&0xff == 0xff {
+		e.run++
+		return
+	}
+	if b&0x100 != 0 && len(e.buf) > 0 {
+		e.buf[len(e.buf)-1]++
+	}
+	for ; e.run > 0; e.run-- {
+		if b&0x100 != 0 {
+			e.buf = append(e.buf, 0x00)
+		} else {
+			e.buf = append(e.buf, 0xff)
+		}
+	}
+	e.buf = append(e.buf, byte(b))
+}
+
+// finish writes the remaining bits and returns the partition.
+func (e *vp8BoolEncoder) finish() []byte {
+	e.putLiteral(0, 9-e.nBits)
+	e.nBits = 0
+	e.flush()
+	return e.buf
+}
+
+// vp8QuantIndex converts a quality from 0 to 100 to a quantizer index from 127 to 0.
+func vp8QuantIndex(quality int) int {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	return (100 - quality) * 127 / 100
+}
+
+// vp8Encoder holds the state used to encode one frame.
+type vp8Encoder struct {
+	mbw, mbh int
+	// y, u, and v are the source planes padded to a whole number of macroblocks.
+	y, u, v           []uint8
+	yStride, uvStride int
+	// ry, ru, and rv are the reconstructed planes as they will be seen by the decoder.
+	ry, ru, rv []uint8
+	// y1, y2, and uv are the DC and AC quantizer step sizes.
+	y1, y2, uv [2]int32
+	modes      *vp8BoolEncoder
+	tokens     *vp8BoolEncoder
+	// topNz and leftNz are whether the blocks bordering each macroblock have non-zero coefficients. Indexes 0-3 are
+	// luma, 4-5 are U, 6-7 are V, and 8 is Y2.
+	topNz  [][9]uint8
+	leftNz [9]uint8
+}
+
+// encodeVP8 encodes the image into a VP8 key frame with the quality from 0 to 100. The alpha channel is ignored.
+func encodeVP8(img *image.NRGBA, quality int) ([]byte, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	e := &vp8Encoder{mbw: (w + 15) / 16, mbh: (h + 15) / 16}
+	e.yStride, e.uvStride = 16*e.mbw, 8*e.mbw
+	e.y, e.ry = make([]uint8, e.yStride*16*e.mbh), make([]uint8, e.yStride*16*e.mbh)
+	e.u, e.ru = make([]uint8, e.uvStride*8*e.mbh), make([]uint8, e.uvStride*8*e.mbh)
+	e.v, e.rv = make([]uint8, e.uvStride*8*e.mbh), make([]uint8, e.uvStride*8*e.mbh)
+	e.convert(img)
+
+	qi := vp8QuantIndex(quality)
+	e.y1 = [2]int32{int32(vp8DCTable[qi]), int32(vp8ACTable[qi])}
+	e.y2 = [2]int32{int32(vp8DCTable[qi]) * 2, int32(vp8ACTable[qi]) * 155 / 100}
+	if e.y2[1] < 8 {
+		e.y2[1] = 8
+	}
+	uvIndex := qi
+	if uvIndex > 117 {
+		uvIndex = 117
+	}
+	e.uv = [2]int32{int32(vp8DCTable[uvIndex]), int32(vp8ACTable[qi])}
+
+	e.modes = newVP8BoolEncoder()
+	e.tokens = newVP8BoolEncoder()
+	e.modes.putLiteral(0, 1) // Color space
+	e.modes.putLiteral(0, 1) // Clamping type
+	e.modes.putLiteral(0, 1) // No segmentation
+	e.modes.putLiteral(0, 1) // Filter type
+	e.modes.putLiteral(0, 6) // Filter level
+	e.modes.putLiteral(0, 3) // Filter sharpness
+	e.modes.putLiteral(0, 1) // No filter deltas
+	e.modes.putLiteral(0, 2) // One token partition
+	e.modes.putLiteral(uint32(qi), 7)
+	for i := 0; i < 5; i++ {
+		e.modes.putLiteral(0, 1) // No quantizer deltas
+	}
+	e.modes.putLiteral(0, 1) // Refresh entropy probabilities
+	for i := range vp8TokenUpdateProb {
+		for j := range vp8TokenUpdateProb[i] {
+			for k := range vp8TokenUpdateProb[i][j] {
+				for _, p := range vp8TokenUpdateProb[i][j][k] {
+					e.modes.putBit(false, p)
+				}
+			}
+		}
+	}
+	e.modes.putLiteral(0, 1) // No skipped macroblocks
+
+	e.topNz = make([][9]uint8, e.mbw)
+	for mby := 0; mby < e.mbh; mby++ {
+		e.leftNz = [9]uint8{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	first := e.modes.finish()
+	// The size of the first partition is stored in 19 bits of the frame tag
+	if len(first) >= 1<<19 {
+		return nil, fmt.Errorf("VP8 first partition is too large: %d bytes", len(first))
+	}
+	data := make([]byte, 10, 10+len(first)+len(e.tokens.buf)+16)
+	tag := uint32(len(first))<<5 | 1<<4 // Key frame, version 0, shown
+	data[0], data[1], data[2] = byte(tag), byte(tag>>8), byte(tag>>16)
+	data[3], data[4], data[5] = 0x9d, 0x01, 0x2a
+	data[6], data[7] = byte(w), byte(w>>8)
+	data[8], data[9] = byte(h), byte(h>>8)
+	data = append(data, first...)
+	return append(data, e.tokens.finish()...), nil
+}
+
+// convert converts the image to the padded YUV planes using the BT.601 conversion used by WebP. The edge pixels are
+// repeated into the padding.
+func (e *vp8Encoder) convert(img *image.NRGBA) {
+	b := img.Bounds()
+	rgb := func(x, y int) (int32, int32, int32) {
+		if x >= b.Dx() {
+			x = b.Dx() - 1
+		}
+		if y >= b.Dy() {
+			y = b.Dy() - 1
+		}
+		i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+		return int32(img.Pix[i]), int32(img.Pix[i+1]), int32(img.Pix[i+2])
+	}
+	for y := 0; y < 16*e.mbh; y++ {
+		for x := 0; x < e.yStride; x++ {
+			r, g, b := rgb(x, y)
+			e.y[y*e.yStride+x] = uint8((16839*r + 33059*g + 6420*b + 1<<15 + 16<<16) >> 16)
+		}
+	}
+	clipUV := func(v int32) uint8 {
+		v = (v + 1<<17 + 128<<18) >> 18
+		if v < 0 {
+			return 0
+		}
+		if v > 255 {
+			return 255
+		}
+		return uint8(v)
+	}
+	for y := 0; y < 8*e.mbh; y++ {
+		for x := 0; x < e.uvStride; x++ {
+			var r, g, b int32
+			for _, pt := range [4]image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgb(2*x+pt.X, 2*y+pt.Y)
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			e.u[y*e.uvStride+x] = clipUV(-9719*r - 19081*g + 28800*b)
+			e.v[y*e.uvStride+x] = clipUV(28800*r - 24116*g - 4684*b)
+		}
+	}
+}
+
+// encodeMacroblock chooses the prediction modes of a macroblock, writes the modes and residual tokens, and
+// reconstructs the macroblock.
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	e.modes.putBit(true, 145) // 16x16 luma prediction
+
+	// Luma
+	x0, y0 := 16*mbx, 16*mby
+	mode, pred := bestVP8Mode(e.y, e.ry, e.yStride, x0, y0, 16)
+	switch mode {
+	case vp8PredDC:
+		e.modes.putBit(false, 156)
+		e.modes.putBit(false, 163)
+	case vp8PredVE:
+		e.modes.putBit(false, 156)
+		e.modes.putBit(true, 163)
+	case vp8PredHE:
+		e.modes.putBit(true, 156)
+		e.modes.putBit(false, 128)
+	case vp8PredTM:
+		e.modes.putBit(true, 156)
+		e.modes.putBit(true, 128)
+	}
+	var coeffs [16][16]int32
+	var dcs [16]int32
+	for n := range coeffs {
+		coeffs[n] = vp8Transform(e.y, e.yStride, x0+4*(n%4), y0+4*(n/4), pred[16*4*(n/4)+4*(n%4):], 16)
+		dcs[n] = coeffs[n][0]
+	}
+	y2Levels, y2Dequant := vp8Quantize(vp8TransformWHT(dcs), e.y2, 0)
+	dcOut := vp8InverseWHT(y2Dequant)
+	var yLevels [16][16]int32
+	for n := range coeffs {
+		var dequant [16]int16
+		yLevels[n], dequant = vp8Quantize(coeffs[n], e.y1, 1)
+		dequant[0] = dcOut[n]
+		vp8Reconstruct(e.ry, e.yStride, x0+4*(n%4), y0+4*(n/4), pred[16*4*(n/4)+4*(n%4):], 16, dequant)
+	}
+
+	// Chroma
+	cx, cy := 8*mbx, 8*mby
+	mode, predU, predV := bestVP8ChromaMode(e, cx, cy)
+	switch mode {
+	case vp8PredDC:
+		e.modes.putBit(false, 142)
+	case vp8PredVE:
+		e.modes.putBit(true, 142)
+		e.modes.putBit(false, 114)
+	case vp8PredHE:
+		e.modes.putBit(true, 142)
+		e.modes.putBit(true, 114)
+		e.modes.putBit(false, 183)
+	case vp8PredTM:
+		e.modes.putBit(true, 142)
+		e.modes.putBit(true, 114)
+		e.modes.putBit(true, 183)
+	}
+	var uvLevels [8][16]int32
+	for i, plane := range []struct {
+		src, recon []uint8
+		pred       []uint8
+	}{{e.u, e.ru, predU}, {e.v, e.rv, predV}} {
+		for n := 0; n < 4; n++ {
+			x, y := cx+4*(n%2), cy+4*(n/2)
+			pred := plane.pred[8*4*(n/2)+4*(n%2):]
+			var dequant [16]int16
+			uvLevels[4*i+n], dequant = vp8Quantize(vp8Transform(plane.src, e.uvStride, x, y, pred, 8), e.uv, 0)
+			vp8Reconstruct(plane.recon, e.uvStride, x, y, pred, 8, dequant)
+		}
+	}
+
+	// Tokens are written in the order Y2, Y, U, V with contexts from the neighboring blocks
+	top := &e.topNz[mbx]
+	nz := e.writeTokens(vp8PlaneY2, e.leftNz[8]+top[8], &y2Levels, 0)
+	e.leftNz[8], top[8] = nz, nz
+	for y := 0; y < 4; y++ {
+		nz := e.leftNz[y]
+		for x := 0; x < 4; x++ {
+			nz = e.writeTokens(vp8PlaneY1WithY2, nz+top[x], &yLevels[4*y+x], 1)
+			top[x] = nz
+		}
+		e.leftNz[y] = nz
+	}
+	for c := 4; c < 8; c += 2 {
+		for y := 0; y < 2; y++ {
+			nz := e.leftNz[c+y]
+			for x := 0; x < 2; x++ {
+				nz = e.writeTokens(vp8PlaneUV, nz+top[c+x], &uvLevels[2*(c-4)+2*y+x], 0)
+				top[c+x] = nz
+			}
+			e.leftNz[c+y] = nz
+		}
+	}
+}
+
+// vp8Edges returns the reconstructed samples above, to the left, and above-left of a block of size n at x, y. The
+// edges of the image use the values defined by the specification.
+func vp8Edges(recon []uint8, stride, x, y, n int) (above, left []uint8, aboveLeft uint8) {
+	above, left = make([]uint8, n), make([]uint8, n)
+	for i := 0; i < n; i++ {
+		above[i], left[i] = 127, 129
+		if y > 0 {
+			above[i] = recon[(y-1)*stride+x+i]
+		}
+		if x > 0 {
+			left[i] = recon[(y+i)*stride+x-1]
+		}
+	}
+	switch {
+	case y == 0:
+		aboveLeft = 127
+	case x == 0:
+		aboveLeft = 129
+	default:
+		aboveLeft = recon[(y-1)*stride+x-1]
+	}
+	return above, left, aboveLeft
+}
+
+// vp8Predict returns the n by n block predicted with the mode from the reconstructed samples bordering the block at
+// x, y.
+func vp8Predict(mode int, recon []uint8, stride, x, y, n int) []uint8 {
+	above, left, aboveLeft := vp8Edges(recon, stride, x, y, n)
+	pred := make([]uint8, n*n)
+	switch mode {
+	case vp8PredDC:
+		sum, count := 0, 0
+		if y > 0 {
+			for _, v := range above {
+				sum += int(v)
+			}
+			count += n
+		}
+		if x > 0 {
+			for _, v := range left {
+				sum += int(v)
+			}
+			count += n
+		}
+		dc := uint8(0x80)
+		if count > 0 {
+			dc = uint8((sum + count/2) / count)
+		}
+		for i := range pred {
+			pred[i] = dc
+		}
+	case vp8PredTM:
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				pred[j*n+i] = uint8(clampByte(int(left[j]) + int(above[i]) - int(aboveLeft)))
+			}
+		}
+	case vp8PredVE:
+		for j := 0; j < n; j++ {
+			copy(pred[j*n:], above)
+		}
+	case vp8PredHE:
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				pred[j*n+i] = left[j]
+			}
+		}
+	}
+	return pred
+}
+
+// vp8Error is the sum of squared differences between the source block and the prediction.
+func vp8Error(src []uint8, stride, x, y int, pred []uint8, n int) int {
+	var sum int
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			d := int(src[(y+j)*stride+x+i]) - int(pred[j*n+i])
+			sum += d * d
+		}
+	}
+	return sum
+}
+
+// bestVP8Mode returns the prediction mode and prediction with the least error for the block.
+func bestVP8Mode(src, recon []uint8, stride, x, y, n int) (int, []uint8) {
+	var best []uint8
+	bestMode, bestErr := 0, -1
+	for mode := vp8PredDC; mode <= vp8PredHE; mode++ {
+		pred := vp8Predict(mode, recon, stride, x, y, n)
+		if err := vp8Error(src, stride, x, y, pred, n); bestErr < 0 || err < bestErr {
+			best, bestMode, bestErr = pred, mode, err
+		}
+	}
+	return bestMode, best
+}
+
+// bestVP8ChromaMode returns the prediction mode with the least combined error for the U and V blocks along with
+// their predictions.
+func bestVP8ChromaMode(e *vp8Encoder, x, y int) (int, []uint8, []uint8) {
+	var bestU, bestV []uint8
+	bestMode, bestErr := 0, -1
+	for mode := vp8PredDC; mode <= vp8PredHE; mode++ {
+		predU := vp8Predict(mode, e.ru, e.uvStride, x, y, 8)
+		predV := vp8Predict(mode, e.rv, e.uvStride, x, y, 8)
+		err := vp8Error(e.u, e.uvStride, x, y, predU, 8) + vp8Error(e.v, e.uvStride, x, y, predV, 8)
+		if bestErr < 0 || err < bestErr {
+			bestU, bestV, bestMode, bestErr = predU, predV, mode, err
+		}
+	}
+	return bestMode, bestU, bestV
+}
+
+// vp8Transform returns the forward DCT of the difference between the 4x4 source block at x, y and the prediction
+// with the given stride.
+func vp8Transform(src []uint8, stride, x, y int, pred []uint8, predStride int) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		var d [4]int32
+		for k := range d {
+			d[k] = int32(src[(y+i)*stride+x+k]) - int32(pred[i*predStride+k])
+		}
+		a0, a1, a2, a3 := d[0]+d[3], d[1]+d[2], d[1]-d[2], d[0]-d[3]
+		tmp[0+i*4] = (a0 + a1) * 8
+		tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[2+i*4] = (a0 - a1) * 8
+		tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+		a2, a3 := tmp[4+i]-tmp[8+i], tmp[0+i]-tmp[12+i]
+		out[0+i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217 + a3*5352 + 12000) >> 16
+		if a3 != 0 {
+			out[4+i]++
+		}
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// vp8TransformWHT returns the forward Walsh-Hadamard transform of the DC coefficients of the 16 luma blocks.
+func vp8TransformWHT(dcs [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		in := dcs[4*i:]
+		a0, a1 := in[0]+in[2], in[1]+in[3]
+		a2, a3 := in[1]-in[3], in[0]-in[2]
+		tmp[0+i*4] = a0 + a1
+		tmp[1+i*4] = a3 + a2
+		tmp[2+i*4] = a3 - a2
+		tmp[3+i*4] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+		a2, a3 := tmp[4+i]-tmp[12+i], tmp[0+i]-tmp[8+i]
+		out[0+i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+	return out
+}
+
+// vp8InverseWHT returns the DC coefficients of the 16 luma blocks from the dequantized Y2 coefficients in the same
+// way as the decoder.
+func vp8InverseWHT(in [16]int16) [16]int16 {
+	var m [16]int32
+	var out [16]int16
+	for i := 0; i < 4; i++ {
+		a0 := int32(in[0+i]) + int32(in[12+i])
+		a1 := int32(in[4+i]) + int32(in[8+i])
+		a2 := int32(in[4+i]) - int32(in[8+i])
+		a3 := int32(in[0+i]) - int32(in[12+i])
+		m[0+i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[0+i*4] + 3
+		a0 := dc + m[3+i*4]
+		a1 := m[1+i*4] + m[2+i*4]
+		a2 := m[1+i*4] - m[2+i*4]
+		a3 := dc - m[3+i*4]
+		out[4*i+0] = int16((a0 + a1) >> 3)
+		out[4*i+1] = int16((a3 + a2) >> 3)
+		out[4*i+2] = int16((a0 - a1) >> 3)
+		out[4*i+3] = int16((a3 - a2) >> 3)
+	}
+	return out
+}
+
+// vp8Quantize quantizes the coefficients starting at the zigzag index first. The levels are returned in zigzag
+// order and the dequantized coefficients the decoder will see are returned in raster order.
+func vp8Quantize(coeffs [16]int32, q [2]int32, first int) ([16]int32, [16]int16) {
+	var levels [16]int32
+	var dequant [16]int16
+	for n := first; n < 16; n++ {
+		z := vp8Zigzag[n]
+		step, bias := q[1], q[1]*3/8
+		if z == 0 {
+			step, bias = q[0], q[0]/2
+		}
+		c := coeffs[z]
+		level := c
+		if level < 0 {
+			level = -level
+		}
+		level = (level + bias) / step
+		if level > vp8MaxLevel {
+			level = vp8MaxLevel
+		}
+		if c < 0 {
+			level = -level
+		}
+		levels[n] = level
+		dequant[z] = int16(level * step)
+	}
+	return levels, dequant
+}
+
+// vp8Reconstruct adds the inverse DCT of the dequantized coefficients to the 4x4 prediction and stores the result
+// in the reconstructed plane in the same way as the decoder.
+func vp8Reconstruct(recon []uint8, stride, x, y int, pred []uint8, predStride int, coeffs [16]int16) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := int32(coeffs[i]) + int32(coeffs[8+i])
+		b := int32(coeffs[i]) - int32(coeffs[8+i])
+		c := (int32(coeffs[4+i])*c2)>>16 - (int32(coeffs[12+i])*c1)>>16
+		d := (int32(coeffs[4+i])*c1)>>16 + (int32(coeffs[12+i])*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := recon[(y+j)*stride+x:]
+		p := pred[j*predStride:]
+		row[0] = uint8(clampByte(int(p[0]) + int((a+d)>>3)))
+		row[1] = uint8(clampByte(int(p[1]) + int((b+c)>>3)))
+		row[2] = uint8(clampByte(int(p[2]) + int((b-c)>>3)))
+		row[3] = uint8(clampByte(int(p[3]) + int((a-d)>>3)))
+	}
+}
+
+// writeTokens writes the quantized levels of a block in zigzag order starting at the index first. The context is
+// the number of neighboring blocks with non-zero coefficients. One is returned if any levels were written.
+func (e *vp8Encoder) writeTokens(plane int, context uint8, levels *[16]int32, first int) uint8 {
+	last := -1
+	for n := 15; n >= first; n-- {
+		if levels[n] != 0 {
+			last = n
+			break
+		}
+	}
+	prob := &vp8DefaultTokenProb[plane]
+	p := &prob[vp8Bands[first]][context]
+	if last < 0 {
+		e.tokens.putBit(false, p[0])
+		return 0
+	}
+	e.tokens.putBit(true, p[0])
+	for n := first; n < 16; {
+		v := levels[n]
+		n++
+		if v == 0 {
+			e.tokens.putBit(false, p[1])
+			p = &prob[vp8Bands[n]][0]
+			continue
+		}
+		e.tokens.putBit(true, p[1])
+		a := v
+		if a < 0 {
+			a = -a
+		}
+		if a == 1 {
+			e.tokens.putBit(false, p[2])
+			p = &prob[vp8Bands[n]][1]
+		} else {
+			e.tokens.putBit(true, p[2])
+			switch {
+			case a <= 4:
+				e.tokens.putBit(false, p[3])
+				if a == 2 {
+					e.tokens.putBit(false, p[4])
+				} else {
+					e.tokens.putBit(true, p[4])
+					e.tokens.putBit(a == 4, p[5])
+				}
+			case a <= 10:
+				e.tokens.putBit(true, p[3])
+				e.tokens.putBit(false, p[6])
+				if a <= 6 {
+					e.tokens.putBit(false, p[7])
+					e.tokens.putBit(a == 6, 159)
+				} else {
+					e.tokens.putBit(true, p[7])
+					e.tokens.putBit((a-7)&2 != 0, 165)
+					e.tokens.putBit((a-7)&1 != 0, 145)
+				}
+			default:
+				e.tokens.putBit(true, p[3])
+				e.tokens.putBit(true, p[6])
+				cat := 0
+				for cat < 3 && a >= 3+(8<<uint(cat+1)) {
+					cat++
+				}
+				e.tokens.putBit(cat >= 2, p[8])
+				e.tokens.putBit(cat&1 == 1, p[9+cat>>1])
+				extra := a - 3 - 8<<uint(cat)
+				tab := vp8Cat3456[cat]
+				count := 0
+				for tab[count] != 0 {
+					count++
+				}
+				for i := 0; i < count; i++ {
+					e.tokens.putBit(extra>>uint(count-1-i)&1 == 1, tab[i])
+				}
+			}
+			p = &prob[vp8Bands[n]][2]
+		}
+		e.tokens.putBit(v < 0, 128)
+		if n == 16 {
+			break
+		}
+		if n > last {
+			e.tokens.putBit(false, p[0])
+			break
+		}
+		e.tokens.putBit(true, p[0])
+	}
+	return 1
+}
+
+// The mapping from coefficient index to band is specified in section 13.3.
+var vp8Bands = [17]uint8{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+
+// vp8Zigzag is the raster position of each coefficient in zigzag order.
+var vp8Zigzag = [16]int{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+// vp8Cat3456 are the probabilities of the extra bits of the larger coefficient categories specified in section 13.2.
+var vp8Cat3456 = [4][12]uint8{
+	{173, 148, 140, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	{176, 155, 140, 135, 0, 0, 0, 0, 0, 0, 0, 0},
+	{180, 157, 141, 134, 130, 0, 0, 0, 0, 0, 0, 0},
+	{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129, 0},
+}
+
+// vp8TokenUpdateProb are the probabilities that each token probability is updated, specified in section 13.4.
+var vp8TokenUpdateProb = [4][8][3][11]uint8{
+	{
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{176, 246, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{223, 241, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 244, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{234, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 246, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{239, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 253, 255, 254, 255, 255, 255, 255, 255, 255},
+			{250, 255, 254, 255, 254, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{217, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{225, 252, 241, 253, 255, 255, 254, 255, 255, 255, 255},
+			{234, 250, 241, 250, 253, 255, 253, 254, 255, 255, 255},
+		},
+		{
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{223, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{238, 253, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{247, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{186, 251, 250, 255, 255, 255, 255, 255, 255, 255, 255},
+			{234, 251, 244, 254, 255, 255, 255, 255, 255, 255, 255},
+			{251, 251, 243, 253, 254, 255, 254, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{236, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 253, 253, 254, 254, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{248, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 254, 252, 254, 255, 255, 255, 255, 255, 255, 255},
+			{248, 254, 249, 253, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{246, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 254, 251, 254, 254, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{248, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{245, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 251, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 252, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+}
+
+// vp8DefaultTokenProb are the default token probabilities specified in section 13.5.
+var vp8DefaultTokenProb = [4][8][3][11]uint8{
+	{
+		{
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{253, 136, 254, 255, 228, 219, 128, 128, 128, 128, 128},
+			{189, 129, 242, 255, 227, 213, 255, 219, 128, 128, 128},
+			{106, 126, 227, 252, 214, 209, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 98, 248, 255, 236, 226, 255, 255, 128, 128, 128},
+			{181, 133, 238, 254, 221, 234, 255, 154, 128, 128, 128},
+			{78, 134, 202, 247, 198, 180, 255, 219, 128, 128, 128},
+		},
+		{
+			{1, 185, 249, 255, 243, 255, 128, 128, 128, 128, 128},
+			{184, 150, 247, 255, 236, 224, 128, 128, 128, 128, 128},
+			{77, 110, 216, 255, 236, 230, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 101, 251, 255, 241, 255, 128, 128, 128, 128, 128},
+			{170, 139, 241, 252, 236, 209, 255, 255, 128, 128, 128},
+			{37, 116, 196, 243, 228, 255, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 204, 254, 255, 245, 255, 128, 128, 128, 128, 128},
+			{207, 160, 250, 255, 238, 128, 128, 128, 128, 128, 128},
+			{102, 103, 231, 255, 211, 171, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 152, 252, 255, 240, 255, 128, 128, 128, 128, 128},
+			{177, 135, 243, 255, 234, 225, 128, 128, 128, 128, 128},
+			{80, 129, 211, 255, 194, 224, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{246, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{255, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+	},
+	{
+		{
+			{198, 35, 237, 223, 193, 187, 162, 160, 145, 155, 62},
+			{131, 45, 198, 221, 172, 176, 220, 157, 252, 221, 1},
+			{68, 47, 146, 208, 149, 167, 221, 162, 255, 223, 128},
+		},
+		{
+			{1, 149, 241, 255, 221, 224, 255, 255, 128, 128, 128},
+			{184, 141, 234, 253, 222, 220, 255, 199, 128, 128, 128},
+			{81, 99, 181, 242, 176, 190, 249, 202, 255, 255, 128},
+		},
+		{
+			{1, 129, 232, 253, 214, 197, 242, 196, 255, 255, 128},
+			{99, 121, 210, 250, 201, 198, 255, 202, 128, 128, 128},
+			{23, 91, 163, 242, 170, 187, 247, 210, 255, 255, 128},
+		},
+		{
+			{1, 200, 246, 255, 234, 255, 128, 128, 128, 128, 128},
+			{109, 178, 241, 255, 231, 245, 255, 255, 128, 128, 128},
+			{44, 130, 201, 253, 205, 192, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 132, 239, 251, 219, 209, 255, 165, 128, 128, 128},
+			{94, 136, 225, 251, 218, 190, 255, 255, 128, 128, 128},
+			{22, 100, 174, 245, 186, 161, 255, 199, 128, 128, 128},
+		},
+		{
+			{1, 182, 249, 255, 232, 235, 128, 128, 128, 128, 128},
+			{124, 143, 241, 255, 227, 234, 128, 128, 128, 128, 128},
+			{35, 77, 181, 251, 193, 211, 255, 205, 128, 128, 128},
+		},
+		{
+			{1, 157, 247, 255, 236, 231, 255, 255, 128, 128, 128},
+			{121, 141, 235, 255, 225, 227, 255, 255, 128, 128, 128},
+			{45, 99, 188, 251, 195, 217, 255, 224, 128, 128, 128},
+		},
+		{
+			{1, 1, 251, 255, 213, 255, 128, 128, 128, 128, 128},
+			{203, 1, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+			{137, 1, 177, 255, 224, 255, 128, 128, 128, 128, 128},
+		},
+	},
+	{
+		{
+			{253, 9, 248, 251, 207, 208, 255, 192, 128, 128, 128},
+			{175, 13, 224, 243, 193, 185, 249, 198, 255, 255, 128},
+			{73, 17, 171, 221, 161, 179, 236, 167, 255, 234, 128},
+		},
+		{
+			{1, 95, 247, 253, 212, 183, 255, 255, 128, 128, 128},
+			{239, 90, 244, 250, 211, 209, 255, 255, 128, 128, 128},
+			{155, 77, 195, 248, 188, 195, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 24, 239, 251, 218, 219, 255, 205, 128, 128, 128},
+			{201, 51, 219, 255, 196, 186, 128, 128, 128, 128, 128},
+			{69, 46, 190, 239, 201, 218, 255, 228, 128, 128, 128},
+		},
+		{
+			{1, 191, 251, 255, 255, 128, 128, 128, 128, 128, 128},
+			{223, 165, 249, 255, 213, 255, 128, 128, 128, 128, 128},
+			{141, 124, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 16, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+			{190, 36, 230, 255, 236, 255, 128, 128, 128, 128, 128},
+			{149, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 226, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{247, 192, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{240, 128, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 134, 252, 255, 255, 128, 128, 128, 128, 128, 128},
+			{213, 62, 250, 255, 255, 128, 128, 128, 128, 128, 128},
+			{55, 93, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+	},
+	{
+		{
+			{202, 24, 213, 235, 186, 191, 220, 160, 240, 175, 255},
+			{126, 38, 182, 232, 169, 184, 228, 174, 255, 187, 128},
+			{61, 46, 138, 219, 151, 178, 240, 170, 255, 216, 128},
+		},
+		{
+			{1, 112, 230, 250, 199, 191, 247, 159, 255, 255, 128},
+			{166, 109, 228, 252, 211, 215, 255, 174, 128, 128, 128},
+			{39, 77, 162, 232, 172, 180, 245, 178, 255, 255, 128},
+		},
+		{
+			{1, 52, 220, 246, 198, 199, 249, 220, 255, 255, 128},
+			{124, 74, 191, 243, 183, 193, 250, 221, 255, 255, 128},
+			{24, 71, 130, 219, 154, 170, 243, 182, 255, 255, 128},
+		},
+		{
+			{1, 182, 225, 249, 219, 240, 255, 224, 128, 128, 128},
+			{149, 150, 226, 252, 216, 205, 255, 171, 128, 128, 128},
+			{28, 108, 170, 242, 183, 194, 254, 223, 255, 255, 128},
+		},
+		{
+			{1, 81, 230, 252, 204, 203, 255, 192, 128, 128, 128},
+			{123, 102, 209, 247, 188, 196, 255, 233, 128, 128, 128},
+			{20, 95, 153, 243, 164, 173, 255, 203, 128, 128, 128},
+		},
+		{
+			{1, 222, 248, 255, 216, 213, 128, 128, 128, 128, 128},
+			{168, 175, 246, 252, 235, 205, 255, 255, 128, 128, 128},
+			{47, 116, 215, 255, 211, 212, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 121, 236, 253, 212, 214, 255, 255, 128, 128, 128},
+			{141, 84, 213, 252, 201, 202, 255, 219, 128, 128, 128},
+			{42, 80, 160, 240, 162, 185, 255, 205, 128, 128, 128},
+		},
+		{
+			{1, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{244, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{238, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+	},
+}
+
+// The quantizer step sizes for each quantizer index are specified in section 14.1.
+var (
+	vp8DCTable = [128]uint16{
+		4, 5, 6, 7, 8, 9, 10, 10,
+		11, 12, 13, 14, 15, 16, 17, 17,
+		18, 19, 20, 20, 21, 21, 22, 22,
+		23, 23, 24, 25, 25, 26, 27, 28,
+		29, 30, 31, 32, 33, 34, 35, 36,
+		37, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 46, 47, 48, 49, 50,
+		51, 52, 53, 54, 55, 56, 57, 58,
+		59, 60, 61, 62, 63, 64, 65, 66,
+		67, 68, 69, 70, 71, 72, 73, 74,
+		75, 76, 76, 77, 78, 79, 80, 81,
+		82, 83, 84, 85, 86, 87, 88, 89,
+		91, 93, 95, 96, 98, 100, 101, 102,
+		104, 106, 108, 110, 112, 114, 116, 118,
+		122, 124, 126, 128, 130, 132, 134, 136,
+		138, 140, 143, 145, 148, 151, 154, 157,
+	}
+	vp8ACTable = [128]uint16{
+		4, 5, 6, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16, 17, 18, 19,
+		20, 21, 22, 23, 24, 25, 26, 27,
+		28, 29, 30, 31, 32, 33, 34, 35,
+		36, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 47, 48, 49, 50, 51,
+		52, 53, 54, 55, 56, 57, 58, 60,
+		62, 64, 66, 68, 70, 72, 74, 76,
+		78, 80, 82, 84, 86, 88, 90, 92,
+		94, 96, 98, 100, 102, 104, 106, 108,
+		110, 112, 114, 116, 119, 122, 125, 128,
+		131, 134, 137, 140, 143, 146, 149, 152,
+		155, 158, 161, 164, 167, 170, 173, 177,
+		181, 185, 189, 193, 197, 201, 205, 209,
+		213, 217, 221, 225, 229, 234, 239, 245,
+		249, 254, 259, 264, 269, 274, 279, 284,
+	}
+)
@@ -0,0 +1,646 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"image"
+	"math/bits"
+	"sort"
+)
+
+// This file implements a lossless VP8L encoder as described in the WebP lossless bitstream specification. Pixels are
+// encoded with the subtract green and predictor transforms followed by LZ77 backward references and prefix codes.
+
+const (
+	vp8lSignature = 0x2f
+	// vp8lPredictorBits is the log-2 size of the tiles that share a predictor mode.
+	vp8lPredictorBits = 4
+	// vp8lMaxCodeLength is the longest prefix code allowed for pixel data.
+	vp8lMaxCodeLength = 15
+	// vp8lMaxCodeLengthCodeLength is the longest prefix code allowed for the code used to write code lengths.
+	vp8lMaxCodeLengthCodeLength = 7
+	vp8lMinMatch                = 3
+	vp8lMaxMatch                = 4096
+	vp8lMaxDistance             = 1<<20 - 120
+	vp8lHashBits                = 16
+	vp8lMaxChain                = 16
+)
+
+// vp8lCodeLengthOrder is the order code length code lengths are written in.
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// bitWriter writes values least significant bit first as used by the VP8L bitstream.
+type bitWriter struct {
+	buf  []byte
+	bits uint64
+	n    uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.bits |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.n -= 8
+	}
+}
+
+// bytes writes any partial byte and returns the written bytes.
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.n = 0, 0
+	}
+	return w.buf
+}
+
+// encodeVP8L encodes the image into a VP8L bitstream including the VP8L header.
+func encodeVP8L(img *image.NRGBA) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	argb := make([]uint32, w*h)
+	hasAlpha := false
+	for y := 0; y < h; y++ {
+		p := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			argb[y*w+x] = uint32(p[4*x+3])<<24 | uint32(p[4*x])<<16 | uint32(p[4*x+1])<<8 | uint32(p[4*x+2])
+			if p[4*x+3] != 0xff {
+				hasAlpha = true
+			}
+		}
+	}
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(w-1), 14)
+	bw.write(uint32(h-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // Version
+	writeVP8LImage(bw, argb, w, h, true)
+	return bw.bytes()
+}
+
+// encodeVP8LAlpha encodes the alpha channel of the image into a VP8L bitstream without the VP8L header as used by
+// the ALPH chunk of lossy images. The alpha values are stored in the green channel.
+func encodeVP8LAlpha(img *image.NRGBA) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	argb := make([]uint32, w*h)
+	for y := 0; y < h; y++ {
+		p := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			argb[y*w+x] = 0xff000000 | uint32(p[4*x+3])<<8
+		}
+	}
+	bw := &bitWriter{}
+	writeVP8LImage(bw, argb, w, h, false)
+	return bw.bytes()
+}
+
+// writeVP8LImage writes the transforms and pixel data of an image. The pixels are modified by the transforms.
+func writeVP8LImage(bw *bitWriter, argb []uint32, w, h int, subtractGreen bool) {
+	if subtractGreen {
+		bw.write(1, 1)
+		bw.write(2, 2)
+		for i, p := range argb {
+			g := (p >> 8) & 0xff
+			argb[i] = p&0xff00ff00 | ((p>>16-g)&0xff)<<16 | (p-g)&0xff
+		}
+	}
+	bw.write(1, 1)
+	bw.write(0, 2)
+	bw.write(vp8lPredictorBits-2, 3)
+	modes, tilesPerRow := vp8lPredict(argb, w, h)
+	writeVP8LPixels(bw, modes, tilesPerRow, false)
+	bw.write(0, 1) // No more transforms
+	writeVP8LPixels(bw, argb, w, true)
+}
+
+// vp8lPredict replaces the pixels with the residuals of the predictor transform. The predictor mode image and its
+// width are returned.
+func vp8lPredict(argb []uint32, w, h int) ([]uint32, int) {
+	tileSize := 1 << vp8lPredictorBits
+	tilesPerRow := (w + tileSize - 1) >> vp8lPredictorBits
+	tilesPerCol := (h + tileSize - 1) >> vp8lPredictorBits
+	modes := make([]uint32, tilesPerRow*tilesPerCol)
+	for ty := 0; ty < tilesPerCol; ty++ {
+		for tx := 0; tx < tilesPerRow; tx++ {
+			best, bestCost := uint32(0), -1
+			for mode := uint32(0); mode < 14; mode++ {
+				cost := 0
+				for y := ty * tileSize; y < (ty+1)*tileSize && y < h; y++ {
+					if y == 0 {
+						continue
+					}
+					for x := tx * tileSize; x < (tx+1)*tileSize && x < w; x++ {
+						if x == 0 {
+							continue
+						}
+						i := y*w + x
+						cost += vp8lResidualCost(vp8lSub(argb[i], vp8lPredictor(mode, argb, i, w)))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesPerRow+tx] = 0xff000000 | best<<8
+		}
+	}
+	// Residuals are computed from the last pixel to the first so that predictions use the original pixels
+	for y := h - 1; y >= 0; y-- {
+		for x := w - 1; x >= 0; x-- {
+			i := y*w + x
+			var pred uint32
+			switch {
+			case x == 0 && y == 0:
+				pred = 0xff000000
+			case y == 0:
+				pred = argb[i-1]
+			case x == 0:
+				pred = argb[i-w]
+			default:
+				mode := (modes[(y>>vp8lPredictorBits)*tilesPerRow+x>>vp8lPredictorBits] >> 8) & 0xff
+				pred = vp8lPredictor(mode, argb, i, w)
+			}
+			argb[i] = vp8lSub(argb[i], pred)
+		}
+	}
+	return modes, tilesPerRow
+}
+
+// vp8lPredictor returns the prediction of the pixel at index i using the predictor mode. The pixel must not be in
+// the first row or column.
+func vp8lPredictor(mode uint32, argb []uint32, i, w int) uint32 {
+	l, t, tl, tr := argb[i-1], argb[i-w], argb[i-w-1], argb[i-w+1]
+	switch mode {
+	case 0:
+		return 0xff000000
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 5:
+		return vp8lAverage(vp8lAverage(l, tr), t)
+	case 6:
+		return vp8lAverage(l, tl)
+	case 7:
+		return vp8lAverage(l, t)
+	case 8:
+		return vp8lAverage(tl, t)
+	case 9:
+		return vp8lAverage(t, tr)
+	case 10:
+		return vp8lAverage(vp8lAverage(l, tl), vp8lAverage(t, tr))
+	case 11:
+		return vp8lSelect(l, t, tl)
+	case 12:
+		return vp8lChannels(func(s uint) uint32 {
+			return clampByte(int(l>>s&0xff) + int(t>>s&0xff) - int(tl>>s&0xff))
+		})
+	default:
+		a := vp8lAverage(l, t)
+		return vp8lChannels(func(s uint) uint32 {
+			return clampByte(int(a>>s&0xff) + (int(a>>s&0xff)-int(tl>>s&0xff))/2)
+		})
+	}
+}
+
+// vp8lChannels combines the values returned for the shift of each channel into a pixel.
+func vp8lChannels(f func(s uint) uint32) uint32 {
+	return f(24)<<24 | f(16)<<16 | f(8)<<8 | f(0)
+}
+
+func vp8lAverage(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func vp8lSelect(l, t, tl uint32) uint32 {
+	var pl, pt int
+	for s := uint(0); s < 32; s += 8 {
+		pl += abs(int(t>>s&0xff) - int(tl>>s&0xff))
+		pt += abs(int(l>>s&0xff) - int(tl>>s&0xff))
+	}
+	if pl < pt {
+		return l
+	}
+	return t
+}
+
+// vp8lSub subtracts each channel of the pixels modulo 256.
+func vp8lSub(a, b uint32) uint32 {
+	return vp8lChannels(func(s uint) uint32 {
+		return (a>>s - b>>s) & 0xff
+	})
+}
+
+// vp8lResidualCost estimates the cost of encoding a residual from the size of its channels.
+func vp8lResidualCost(r uint32) int {
+	var cost int
+	for s := uint(0); s < 32; s += 8 {
+		cost += abs(int(int8(r >> s)))
+	}
+	return cost
+}
+
+func clampByte(v int) uint32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint32(v)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// vp8lToken is a literal pixel or a backward reference to earlier pixels.
+type vp8lToken struct {
+	// argb is the literal pixel if length is zero.
+	argb uint32
+	// length is the number of pixels copied by a backward reference.
+	length int
+	// dist is the distance code of a backward reference.
+	dist int
+}
+
+// vp8lTokens finds backward references in the pixels using a hash chain of the previous pixels.
+func vp8lTokens(argb []uint32, w int) []vp8lToken {
+	n := len(argb)
+	head := make([]int32, 1<<vp8lHashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, n)
+	hash := func(i int) uint32 {
+		return (argb[i]*0x1e35a7bd ^ argb[i+1]*0x9e3779b1) >> (32 - vp8lHashBits)
+	}
+	insert := func(i int) {
+		if i+1 < n {
+			h := hash(i)
+			prev[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+	matchLength := func(i, j int) int {
+		max := n - i
+		if max > vp8lMaxMatch {
+			max = vp8lMaxMatch
+		}
+		l := 0
+		for l < max && argb[i+l] == argb[j+l] {
+			l++
+		}
+		return l
+	}
+
+	tokens := make([]vp8lToken, 0, n/4)
+	for i := 0; i < n; {
+		bestLength, bestDist := 0, 0
+		try := func(dist int) {
+			if dist <= 0 || dist > i || dist > vp8lMaxDistance {
+				return
+			}
+			if l := matchLength(i, i-dist); l > bestLength {
+				bestLength, bestDist = l, dist
+			}
+		}
+		try(1)
+		try(w)
+		if i+1 < n {
+			for j, k := head[hash(i)], 0; j >= 0 && k < vp8lMaxChain && bestLength < vp8lMaxMatch; j, k = prev[j], k+1 {
+				try(i - int(j))
+			}
+		}
+		if bestLength < vp8lMinMatch {
+			tokens = append(tokens, vp8lToken{argb: argb[i]})
+			insert(i)
+			i++
+			continue
+		}
+		dist := bestDist + 120
+		switch bestDist {
+		case w:
+			dist = 1
+		case 1:
+			dist = 2
+		}
+		tokens = append(tokens, vp8lToken{length: bestLength, dist: dist})
+		for j := i; j < i+bestLength; j++ {
+			insert(j)
+		}
+		i += bestLength
+	}
+	return tokens
+}
+
+// vp8lPrefix returns the prefix code, the number of extra bits, and the extra bits used to write an LZ77 length or
+// distance code.
+func vp8lPrefix(v int) (int, uint, uint32) {
+	v--
+	if v < 4 {
+		return v, 0, 0
+	}
+	high := bits.Len(uint(v)) - 1
+	second := (v >> uint(high-1)) & 1
+	return 2*high + second, uint(high - 1), uint32(v & (1<<uint(high-1) - 1))
+}
+
+// writeVP8LPixels writes the pixels as entropy-coded image data.
+func writeVP8LPixels(bw *bitWriter, argb []uint32, w int, topLevel bool) {
+	bw.write(0, 1) // No color cache
+	if topLevel {
+		bw.write(0, 1) // No meta prefix codes
+	}
+	tokens := vp8lTokens(argb, w)
+	green, red, blue, alpha, dist := make([]int, 256+24), make([]int, 256), make([]int, 256), make([]int, 256),
+		make([]int, 40)
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[t.argb>>8&0xff]++
+			red[t.argb>>16&0xff]++
+			blue[t.argb&0xff]++
+			alpha[t.argb>>24]++
+			continue
+		}
+		lengthPrefix, _, _ := vp8lPrefix(t.length)
+		green[256+lengthPrefix]++
+		distPrefix, _, _ := vp8lPrefix(t.dist)
+		dist[distPrefix]++
+	}
+	codes := [5]*prefixCode{}
+	for i, histogram := range [][]int{green, red, blue, alpha, dist} {
+		codes[i] = writePrefixCode(bw, histogram)
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].write(bw, int(t.argb>>8&0xff))
+			codes[1].write(bw, int(t.argb>>16&0xff))
+			codes[2].write(bw, int(t.argb&0xff))
+			codes[3].write(bw, int(t.argb>>24))
+			continue
+		}
+		prefix, n, extra := vp8lPrefix(t.length)
+		codes[0].write(bw, 256+prefix)
+		bw.write(extra, n)
+		prefix, n, extra = vp8lPrefix(t.dist)
+		codes[4].write(bw, prefix)
+		bw.write(extra, n)
+	}
+}
+
+// prefixCode is a canonical Huffman code.
+type prefixCode struct {
+	// lengths are the code lengths of each symbol. Symbols are written with no bits if the code only has one
+	// symbol.
+	lengths []uint8
+	// codes are the bit-reversed codes of each symbol.
+	codes []uint16
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol int) {
+	bw.write(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+}
+
+// newPrefixCode builds a prefix code from the symbol histogram with codes no longer than the limit.
+func newPrefixCode(histogram []int, limit int) *prefixCode {
+	lengths := huffmanLengths(histogram, limit)
+	var count, next [16]int
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+		}
+	}
+	code := 0
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	codes := make([]uint16, len(lengths))
+	for symbol, l := range lengths {
+		if l > 0 {
+			codes[symbol] = uint16(bits.Reverse16(uint16(next[l])) >> (16 - l))
+			next[l]++
+		}
+	}
+	return &prefixCode{lengths: lengths, codes: codes}
+}
+
+// huffmanLengths returns Huffman code lengths for the symbol histogram. The histogram is flattened until no code is
+// longer than the limit. A single used symbol is given a length of one.
+func huffmanLengths(histogram []int, limit int) []uint8 {
+	weights := append([]int(nil), histogram...)
+	for {
+		lengths := make([]uint8, len(weights))
+		var symbols []int
+		for s, w := range weights {
+			if w > 0 {
+				symbols = append(symbols, s)
+			}
+		}
+		if len(symbols) <= 1 {
+			for _, s := range symbols {
+				lengths[s] = 1
+			}
+			return lengths
+		}
+		sort.SliceStable(symbols, func(i, j int) bool { return weights[symbols[i]] < weights[symbols[j]] })
+
+		// Leaves are nodes 0 to k-1 in increasing weight followed by the internal nodes in the order they're created,
+		// which is also increasing weight.
+		k := len(symbols)
+		weight := make([]int, 2*k-1)
+		parent := make([]int, 2*k-1)
+		for i, s := range symbols {
+			weight[i] = weights[s]
+		}
+		leaf, internal := 0, k
+		smallest := func(next int) int {
+			if leaf < k && (internal >= next || weight[leaf] <= weight[internal]) {
+				leaf++
+				return leaf - 1
+			}
+			internal++
+			return internal - 1
+		}
+		for next := k; next < 2*k-1; next++ {
+			a := smallest(next)
+			b := smallest(next)
+			weight[next] = weight[a] + weight[b]
+			parent[a], parent[b] = next, next
+		}
+		depth := make([]int, 2*k-1)
+		longest := 0
+		for i := 2*k - 3; i >= 0; i-- {
+			depth[i] = depth[parent[i]] + 1
+			if i < k {
+				lengths[symbols[i]] = uint8(depth[i])
+				if depth[i] > longest {
+					longest = depth[i]
+				}
+			}
+		}
+		if longest <= limit {
+			return lengths
+		}
+		for s, w := range weights {
+			if w > 0 {
+				weights[s] = (w + 1) / 2
+			}
+		}
+	}
+}
+
+// writePrefixCode builds a prefix code from the symbol histogram and writes it. The code is returned ready to
+// write symbols with.
+func writePrefixCode(bw *bitWriter, histogram []int) *prefixCode {
+	code := newPrefixCode(histogram, vp8lMaxCodeLength)
+	var symbols []int
+	for s, l := range code.lengths {
+		if l > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		// Unused codes are written as a simple code with the single symbol zero
+		bw.write(1, 1)
+		bw.write(0, 1)
+		bw.write(0, 1)
+		bw.write(0, 1)
+		return code
+	}
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		bw.write(1, 1)
+		bw.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbols[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			bw.write(uint32(symbols[1]), 8)
+		}
+	} else {
+		writeCodeLengths(bw, code.lengths)
+	}
+	if len(symbols) == 1 {
+		code.lengths[symbols[0]] = 0
+	}
+	return code
+}
+
+// writeCodeLengths writes the code lengths of a normal prefix code using run-length codes.
+func writeCodeLengths(bw *bitWriter, lengths []uint8) {
+	type run struct {
+		symbol int
+		extra  uint32
+	}
+	var runs []run
+	prev := uint8(8)
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		n := 1
+		for i+n < len(lengths) && lengths[i+n] == l {
+			n++
+		}
+		i += n
+		if l == 0 {
+			for n >= 11 {
+				r := n
+				if r > 138 {
+					r = 138
+				}
+				runs = append(runs, run{symbol: 18, extra: uint32(r - 11)})
+				n -= r
+			}
+			if n >= 3 {
+				runs = append(runs, run{symbol: 17, extra: uint32(n - 3)})
+				n = 0
+			}
+		} else {
+			if l != prev {
+				runs = append(runs, run{symbol: int(l)})
+				prev = l
+				n--
+			}
+			for n >= 3 {
+				r := n
+				if r > 6 {
+					r = 6
+				}
+				runs = append(runs, run{symbol: 16, extra: uint32(r - 3)})
+				n -= r
+			}
+		}
+		for ; n > 0; n-- {
+			runs = append(runs, run{symbol: int(l)})
+		}
+	}
+
+	histogram := make([]int, 19)
+	for _, r := range runs {
+		histogram[r.symbol]++
+	}
+	code := newPrefixCode(histogram, vp8lMaxCodeLengthCodeLength)
+	n := len(vp8lCodeLengthOrder)
+	for n > 4 && code.lengths[vp8lCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	bw.write(0, 1) // Normal code
+	bw.write(uint32(n-4), 4)
+	for _, s := range vp8lCodeLengthOrder[:n] {
+		bw.write(uint32(code.lengths[s]), 3)
+	}
+	bw.write(0, 1) // Every symbol is written
+	var used int
+	for _, l := range code.lengths {
+		if l > 0 {
+			used++
+		}
+	}
+	if used == 1 {
+		for s := range code.lengths {
+			code.lengths[s] = 0
+		}
+	}
+	for _, r := range runs {
+		code.write(bw, r.symbol)
+		switch r.symbol {
+		case 16:
+			bw.write(r.extra, 2)
+		case 17:
+			bw.write(r.extra, 3)
+		case 18:
+			bw.write(r.extra, 7)
+		}
+	}
+}
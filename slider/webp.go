@@ -0,0 +1,230 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/rs/zerolog/log"
+	"image"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultWebPQuality is the lossy compression quality used for WebP animations if no quality is given.
+const DefaultWebPQuality = 75
+
+// maxWebPDimension is the largest width or height of a WebP frame.
+const maxWebPDimension = 16383
+
+// WebPOptions are the options used to encode WebP animations.
+type WebPOptions struct {
+	// Lossless encodes frames with lossless compression. Frames are encoded with lossy compression otherwise.
+	Lossless bool
+	// Quality is the lossy compression quality from 1 to 100. Higher qualities create larger files.
+	// DefaultWebPQuality is used if Quality is zero.
+	Quality int
+}
+
+// WebPFrame is a single frame of a WebP animation.
+type WebPFrame struct {
+	// Delay is the time to display the frame in hundredths of a second.
+	Delay int
+	// Image is the frame image.
+	Image image.Image
+}
+
+// WebPAnimation is an animated WebP image. Frames are compressed when the animation is encoded.
+type WebPAnimation struct {
+	Frames []WebPFrame
+	// LoopCount is the number of times the animation is played. The animation is played forever if LoopCount is
+	// zero.
+	LoopCount int
+	Options   WebPOptions
+}
+
+// AnimateWebP animates the supplied images into a WebP image.
+func AnimateWebP(images []image.Image, delay int, style LoopStyle, opts *WebPOptions) (*WebPAnimation, error) {
+	animation := &WebPAnimation{Options: *opts}
+	if animation.Options.Quality == 0 {
+		animation.Options.Quality = DefaultWebPQuality
+	}
+	if animation.Options.Quality < 1 || animation.Options.Quality > 100 {
+		return nil, fmt.Errorf("WebP quality must be between 1 and 100: %d", animation.Options.Quality)
+	}
+	for _, img := range images {
+		if size := img.Bounds().Size(); size.X > maxWebPDimension || size.Y > maxWebPDimension {
+			return nil, fmt.Errorf("WebP frames can't be larger than %dx%d: %dx%d", maxWebPDimension,
+				maxWebPDimension, size.X, size.Y)
+		}
+	}
+	switch style {
+	case RockLoop:
+		animation.Frames = make([]WebPFrame, len(images)*2)
+	case ForwardLoop, ReverseLoop:
+		animation.Frames = make([]WebPFrame, len(images))
+	default:
+		return nil, fmt.Errorf("unknown animation loop style: %v", style)
+	}
+	for i, img := range images {
+		switch style {
+		case ForwardLoop:
+			animation.Frames[i] = WebPFrame{Delay: delay, Image: img}
+		case ReverseLoop:
+			animation.Frames[len(images)-1-i] = WebPFrame{Delay: delay, Image: img}
+		case RockLoop:
+			animation.Frames[i] = WebPFrame{Delay: delay, Image: img}
+			animation.Frames[(len(images)*2)-1-i] = WebPFrame{Delay: delay, Image: img}
+		}
+	}
+	return animation, nil
+}
+
+// EncodeWebP writes the animation to w in the animated WebP format. Each frame is drawn over the whole canvas in
+// the upper-left-hand corner.
+func EncodeWebP(w io.Writer, animation *WebPAnimation) error {
+	if len(animation.Frames) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+	log.Debug().Msgf("Encoding %d WebP frames", len(animation.Frames))
+	timeIn := time.Now()
+	frames := make([][]byte, len(animation.Frames))
+	hasAlpha := make([]bool, len(animation.Frames))
+	errs := make([]error, len(animation.Frames))
+	wg := sync.WaitGroup{}
+	for i, frame := range animation.Frames {
+		wg.Add(1)
+		go func(i int, img image.Image) {
+			frames[i], hasAlpha[i], errs[i] = encodeWebPFrame(img, &animation.Options)
+			wg.Done()
+		}(i, frame.Image)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("unable to encode WebP frame %d: %w", i, err)
+		}
+	}
+
+	var canvas image.Point
+	var flags byte = 0x02 // Animation
+	for i, frame := range animation.Frames {
+		size := frame.Image.Bounds().Size()
+		if size.X > canvas.X {
+			canvas.X = size.X
+		}
+		if size.Y > canvas.Y {
+			canvas.Y = size.Y
+		}
+		if hasAlpha[i] {
+			flags |= 0x10
+		}
+	}
+
+	data := []byte("WEBP")
+	vp8x := []byte{flags, 0, 0, 0}
+	vp8x = appendUint24(vp8x, canvas.X-1)
+	vp8x = appendUint24(vp8x, canvas.Y-1)
+	data = appendChunk(data, "VP8X", vp8x)
+	// The background color is transparent
+	data = appendChunk(data, "ANIM", appendUint16([]byte{0, 0, 0, 0}, animation.LoopCount))
+	for i, frame := range animation.Frames {
+		size := frame.Image.Bounds().Size()
+		anmf := appendUint24(nil, 0)
+		anmf = appendUint24(anmf, 0)
+		anmf = appendUint24(anmf, size.X-1)
+		anmf = appendUint24(anmf, size.Y-1)
+		anmf = appendUint24(anmf, frame.Delay*10)
+		anmf = append(anmf, 0x02) // Don't blend with the previous frame and don't dispose
+		data = appendChunk(data, "ANMF", append(anmf, frames[i]...))
+	}
+
+	header := append([]byte("RIFF"), 0, 0, 0, 0)
+	putUint32(header[4:], len(data))
+	_, err := w.Write(append(header, data...))
+	if err != nil {
+		return fmt.Errorf("unable to write WebP: %w", err)
+	}
+	timeOut := time.Now()
+	log.Debug().Msgf("Encoding took %.3fs", timeOut.Sub(timeIn).Seconds())
+	return nil
+}
+
+// encodeWebPFrame encodes the image into the chunks of a single frame and returns whether the frame has
+// transparency.
+func encodeWebPFrame(img image.Image, opts *WebPOptions) ([]byte, bool, error) {
+	nrgba := imaging.Clone(img)
+	hasAlpha := !nrgba.Opaque()
+	if opts.Lossless {
+		return appendChunk(nil, "VP8L", encodeVP8L(nrgba)), hasAlpha, nil
+	}
+	var data []byte
+	if hasAlpha {
+		// The alpha values are compressed losslessly without filtering or pre-processing
+		data = appendChunk(data, "ALPH", append([]byte{0x01}, encodeVP8LAlpha(nrgba)...))
+	}
+	vp8, err := encodeVP8(nrgba, opts.Quality)
+	if err != nil {
+		return nil, false, err
+	}
+	return appendChunk(data, "VP8 ", vp8), hasAlpha, nil
+}
+
+// appendChunk appends a RIFF chunk with the FourCC and data. Chunks are padded to an even size.
+func appendChunk(buf []byte, fourCC string, data []byte) []byte {
+	buf = append(buf, fourCC...)
+	buf = append(buf, 0, 0, 0, 0)
+	putUint32(buf[len(buf)-4:], len(data))
+	buf = append(buf, data...)
+	if len(data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func appendUint24(buf []byte, v int) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16))
+}
+
+func putUint32(buf []byte, v int) {
+	buf[0], buf[1], buf[2], buf[3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+}
+
+// SaveWebP encodes the WebP animation into a .webp file. The .webp extension will be added automatically. If a file
+// with the same name exists an incrementing number will be appended to the end of the file name.
+func SaveWebP(output string, animation *WebPAnimation) (string, error) {
+	output, err := checkFileDuplicate(output, ".webp")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(output+".webp", os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to open WebP file: %w", err)
+	}
+	err = EncodeWebP(f, animation)
+	if err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("unable to encode WebP: %w", err)
+	}
+	log.Debug().Msgf("Saved WebP to '%s'", output+".webp")
+	err = f.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to close WebP file: %w", err)
+	}
+	return output + ".webp", nil
+}
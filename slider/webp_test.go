@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Colin McIntosh
+// Author: Colin McIntosh (colin@colinmcintosh.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testWebPImage returns an image with gradients, a noisy area, and a partially transparent corner.
+func testWebPImage(w, h int, alpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	seed := uint32(1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: 128, A: 255}
+			if x > w/2 && y > h/2 {
+				seed = seed*1103515245 + 12345
+				c.B = uint8(seed >> 16)
+			}
+			if alpha && x < w/3 && y < h/3 {
+				c.A = uint8(x * 20)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+type webpChunk struct {
+	fourCC string
+	data   []byte
+}
+
+// webpChunks splits the RIFF WebP data into chunks.
+func webpChunks(t *testing.T, data []byte) []webpChunk {
+	var chunks []webpChunk
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 8)
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		require.LessOrEqual(t, 8+size, len(data))
+		chunks = append(chunks, webpChunk{fourCC: string(data[:4]), data: data[8 : 8+size]})
+		data = data[8+size+size%2:]
+	}
+	return chunks
+}
+
+// decodeWebPFrames decodes each frame of the animation by wrapping the frame in a still WebP image.
+func decodeWebPFrames(t *testing.T, data []byte) []image.Image {
+	require.Equal(t, "RIFF", string(data[:4]))
+	require.Equal(t, len(data)-8, int(binary.LittleEndian.Uint32(data[4:])))
+	require.Equal(t, "WEBP", string(data[8:12]))
+	var images []image.Image
+	for _, chunk := range webpChunks(t, data[12:]) {
+		if chunk.fourCC != "ANMF" {
+			continue
+		}
+		width := int(chunk.data[6]) | int(chunk.data[7])<<8 | int(chunk.data[8])<<16 + 1
+		height := int(chunk.data[9]) | int(chunk.data[10])<<8 | int(chunk.data[11])<<16 + 1
+		still := []byte("WEBP")
+		if chunk.data[16:20][0] == 'A' {
+			// Frames with an ALPH chunk need a VP8X chunk
+			still = appendChunk(still, "VP8X", appendUint24(appendUint24([]byte{0x10, 0, 0, 0}, width-1), height-1))
+		}
+		still = append(still, chunk.data[16:]...)
+		header := append([]byte("RIFF"), 0, 0, 0, 0)
+		putUint32(header[4:], len(still))
+		img, err := webp.Decode(bytes.NewReader(append(header, still...)))
+		require.NoError(t, err)
+		images = append(images, img)
+	}
+	return images
+}
+
+func TestEncodeWebP(t *testing.T) {
+	images := []image.Image{testWebPImage(37, 23, true), testWebPImage(37, 23, false)}
+	animation, err := AnimateWebP(images, 15, RockLoop, &WebPOptions{Lossless: true})
+	require.NoError(t, err)
+	require.Len(t, animation.Frames, 4)
+	assert.Equal(t, DefaultWebPQuality, animation.Options.Quality)
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeWebP(&buf, animation))
+	chunks := webpChunks(t, buf.Bytes()[12:])
+	require.Len(t, chunks, 6)
+	assert.Equal(t, "VP8X", chunks[0].fourCC)
+	assert.Equal(t, []byte{0x12, 0, 0, 0, 36, 0, 0, 22, 0, 0}, chunks[0].data, "Animation and alpha flags should be set")
+	assert.Equal(t, "ANIM", chunks[1].fourCC)
+	for _, chunk := range chunks[2:] {
+		assert.Equal(t, "ANMF", chunk.fourCC)
+		assert.Equal(t, []byte{150, 0, 0}, chunk.data[12:15], "Frame duration should be in milliseconds")
+	}
+
+	// Lossless frames should decode to the exact pixels
+	decoded := decodeWebPFrames(t, buf.Bytes())
+	require.Len(t, decoded, 4)
+	for i, img := range decoded {
+		expected := images[[]int{0, 1, 1, 0}[i]].(*image.NRGBA)
+		nrgba, ok := img.(*image.NRGBA)
+		require.True(t, ok, "Lossless frames should decode as NRGBA")
+		assert.Equal(t, expected.Pix, nrgba.Pix, "Frame %d should be lossless", i)
+	}
+
+	_, err = AnimateWebP(images, 15, ForwardLoop, &WebPOptions{Quality: 101})
+	assert.Error(t, err, "Invalid quality should fail")
+}
+
+func TestEncodeWebPLossy(t *testing.T) {
+	src := testWebPImage(53, 41, true)
+	var sizes []int
+	for _, quality := range []int{30, 90} {
+		animation, err := AnimateWebP([]image.Image{src}, 10, ForwardLoop, &WebPOptions{Quality: quality})
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, EncodeWebP(&buf, animation))
+		sizes = append(sizes, buf.Len())
+		decoded := decodeWebPFrames(t, buf.Bytes())
+		require.Len(t, decoded, 1)
+		img, ok := decoded[0].(*image.NYCbCrA)
+		require.True(t, ok, "Lossy frames with transparency should decode with alpha")
+		assert.Equal(t, src.Bounds(), img.Bounds())
+
+		var sum float64
+		for y := 0; y < 41; y++ {
+			for x := 0; x < 53; x++ {
+				c := src.NRGBAAt(x, y)
+				expected := (16839*int(c.R) + 33059*int(c.G) + 6420*int(c.B) + (16 << 16) + (1 << 15)) >> 16
+				sum += float64(abs(expected - int(img.Y[img.YOffset(x, y)])))
+				require.Equal(t, c.A, img.A[img.AOffset(x, y)], "Alpha should be lossless at %d,%d", x, y)
+			}
+		}
+		assert.Less(t, sum/(53*41), []float64{8, 2}[len(sizes)-1], "Quality %d luma error is too large", quality)
+	}
+	assert.Less(t, sizes[0], sizes[1], "Lower qualities should be smaller")
+}